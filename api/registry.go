@@ -2,10 +2,18 @@ package api
 
 import (
 	"twitter-bff/openapigen"
+	"twitter-bff/openapigen/admin"
 	"twitter-bff/pkg/http"
 	"twitter-bff/usecases"
 )
 
-func Registry(provider *http.Server, serverImpl *usecases.EchoServer) {
-	openapigen.RegisterHandlersWithBaseURL(provider.Echo(), serverImpl, "/api")
+const (
+	BaseURL      = "/api"
+	AdminBaseURL = "/api/admin"
+)
+
+func Registry(provider *http.Server, serverImpl *usecases.EchoServer, adminImpl *usecases.AdminServer) {
+	openapigen.RegisterHandlersWithBaseURL(provider.Echo(), serverImpl, BaseURL)
+	admin.RegisterHandlersWithBaseURL(provider.Echo(), adminImpl, AdminBaseURL)
+	provider.Echo().GET(http.JWKSPath, serverImpl.JWKS)
 }
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"time"
+)
+
+// EmailVerificationAudience audience токена из ссылки подтверждения email
+const EmailVerificationAudience = "twitter-bff:verify-email"
+
+var (
+	ErrEmailNotVerified     = errors.New("email is not verified")
+	ErrEmailAlreadyVerified = errors.New("email is already verified")
+	ErrTooManyRequests      = errors.New("too many requests")
+	ErrInvalidOneTimeToken  = errors.New("invalid or expired token")
+)
+
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// RateLimitedError сообщает, через сколько можно повторить запрос
+type RateLimitedError struct {
+	RetryAfter time.Duration
+}
+
+func (e RateLimitedError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", ErrTooManyRequests, e.RetryAfter)
+}
+
+func (e RateLimitedError) Is(target error) bool {
+	return target == ErrTooManyRequests
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"golang.org/x/text/unicode/norm"
+	"strings"
+	"unicode"
+)
+
+const (
+	FieldTag    = "tag"
+	FieldPrefix = "prefix"
+)
+
+const maxHashtagLength = 100
+
+// Hashtag тег и количество постов с ним
+type Hashtag struct {
+	Tag       string
+	PostCount int32
+}
+
+// ExtractHashtags теги из текста поста в порядке появления, без повторов и без '#'.
+// Тег начинается с '#' в начале текста или после символа, который не может быть частью слова,
+// и состоит из букв любого алфавита, цифр, '_' и диакритических знаков. Теги из одних цифр
+// и длиннее 100 символов не учитываются. Теги приводятся к NormalizeHashtag.
+func ExtractHashtags(body string) []string {
+	runes := []rune(norm.NFC.String(body))
+
+	tags := make([]string, 0)
+	seen := make(map[string]struct{})
+
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '#' || (i > 0 && (isHashtagRune(runes[i-1]) || runes[i-1] == '#' || runes[i-1] == '&')) {
+			continue
+		}
+
+		end := i + 1
+		for end < len(runes) && isHashtagRune(runes[end]) {
+			end++
+		}
+
+		tag := string(runes[i+1 : end])
+		i = end - 1
+
+		if !validHashtag(tag) {
+			continue
+		}
+
+		tag = strings.ToLower(tag)
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+
+		seen[tag] = struct{}{}
+		tags = append(tags, tag)
+	}
+
+	return tags
+}
+
+// NormalizeHashtag приводит тег из запроса к виду, в котором он хранится: без '#', в NFC и нижнем регистре
+func NormalizeHashtag(tag string) (string, error) {
+	tag = normalizeTag(tag)
+
+	if !validHashtag(tag) || strings.IndexFunc(tag, func(r rune) bool { return !isHashtagRune(r) }) >= 0 {
+		return "", ValidationError{Fields: []FieldError{{
+			Field:   FieldTag,
+			Code:    ErrCodeInvalid,
+			Message: "hashtag must consist of letters, digits and underscores and contain a letter",
+		}}}
+	}
+
+	return tag, nil
+}
+
+// NormalizeHashtagPrefix начало тега для подсказок. В отличие от тега может состоять из одних цифр.
+func NormalizeHashtagPrefix(prefix string) (string, error) {
+	prefix = normalizeTag(prefix)
+
+	if len(prefix) == 0 || strings.IndexFunc(prefix, func(r rune) bool { return !isHashtagRune(r) }) >= 0 {
+		return "", ValidationError{Fields: []FieldError{{
+			Field:   FieldPrefix,
+			Code:    ErrCodeInvalid,
+			Message: "prefix must be a non-empty beginning of a hashtag",
+		}}}
+	}
+
+	return prefix, nil
+}
+
+func normalizeTag(tag string) string {
+	return strings.ToLower(norm.NFC.String(strings.TrimPrefix(strings.TrimSpace(tag), "#")))
+}
+
+func validHashtag(tag string) bool {
+	length := len([]rune(tag))
+
+	return length > 0 && length <= maxHashtagLength && strings.IndexFunc(tag, unicode.IsLetter) >= 0
+}
+
+func isHashtagRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r) || r == '_'
+}
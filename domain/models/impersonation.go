@@ -0,0 +1,31 @@
+package models
+
+import (
+	"github.com/pkg/errors"
+	"time"
+)
+
+const FieldReason = "reason"
+
+const (
+	AuditActionImpersonationStart   = "impersonation.start"
+	AuditActionImpersonatedRequest  = "impersonation.request"
+	AuditActionImpersonationBlocked = "impersonation.blocked"
+)
+
+var ErrImpersonationNotAllowed = errors.New("operation is not allowed while impersonating")
+
+// AuditEntry запись журнала действий администраторов
+type AuditEntry struct {
+	Time time.Time
+	// ActorID администратор, который выполняет действие
+	ActorID int32
+	// UserID пользователь, от имени или над которым выполняется действие
+	UserID int32
+	Action string
+	Method string
+	Path   string
+	Status int
+	IP     string
+	Reason string
+}
@@ -1,24 +1,101 @@
 package models
 
 import (
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"strconv"
 	"time"
 )
 
 var (
-	ErrInvalidUserID = errors.New("invalid user id")
-	ErrTokenExpired  = errors.New("token expired")
+	ErrInvalidUserID       = errors.New("invalid user id")
+	ErrTokenExpired        = errors.New("token expired")
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reused")
+	ErrNotAccessToken      = errors.New("not an access token")
 )
 
-const JWTCookieName = "user-jwt"
+// AccessTokenAudience audience access токена. Тем же ключом подписываются токены для других целей
+// (подтверждение email, ввод кода 2FA) со своим audience, поэтому сервисы, проверяющие токены
+// по JWKS, должны требовать именно это значение.
+const AccessTokenAudience = "twitter-bff:access"
+
+const (
+	JWTCookieName          = "user-jwt"
+	RefreshTokenCookieName = "user-refresh-token"
+	JWTUserContextKey      = "jwtUser"
+	// CSRFCookieName читается фронтендом и возвращается в заголовке CSRFHeaderName
+	CSRFCookieName = "csrf-token"
+	CSRFHeaderName = "X-CSRF-Token"
+)
 
 type JWTToken struct {
-	Token string
+	UserID           int32
+	SessionID        string
+	AccessToken      string
+	AccessExpiredAt  time.Time
+	RefreshToken     string
+	RefreshExpiredAt time.Time
+	// CSRFToken хэш этого значения записан в access токен в claim csrf
+	CSRFToken string
+}
+
+// RefreshToken хранится только в виде хэша, сам токен отдается клиенту один раз.
+// Все токены, полученные ротацией из одного логина, принадлежат одному семейству.
+type RefreshToken struct {
+	Hash      string
+	FamilyID  string
+	UserID    int32
+	ExpiredAt time.Time
+	Used      bool
 }
 
 type JWTUser struct {
+	// ID значение claim jti, по нему токен отзывается
+	ID        string
 	UserID    int32
+	IssuedAt  time.Time
 	ExpiredAt time.Time
+	// SessionID значение claim sid
+	SessionID string
+	// CSRFHash значение claim csrf
+	CSRFHash string
+	// PersonalToken пользователь аутентифицирован персональным токеном с правами Scopes
+	PersonalToken bool
+	Scopes        []string
+	// Roles значение claim roles
+	Roles []string
+	// ActorID администратор из claim act, который действует от имени пользователя. 0 - обычный вход
+	ActorID int32
+}
+
+func (u JWTUser) IsImpersonated() bool {
+	return u.ActorID != 0
+}
+
+// HasScope проверяет право персонального токена. Токену сессии доступно все.
+func (u JWTUser) HasScope(scope string) bool {
+	if !u.PersonalToken {
+		return true
+	}
+
+	return len(scope) != 0 && lo.Contains(u.Scopes, scope)
+}
+
+// HasPermission проверяет право по ролям токена. Персональным токенам права ролей не передаются.
+func (u JWTUser) HasPermission(permission string) bool {
+	if u.PersonalToken {
+		return false
+	}
+
+	for _, role := range u.Roles {
+		if lo.Contains(RolePermissions[role], permission) {
+			return true
+		}
+	}
+
+	return false
 }
 
 func (u JWTUser) IsOK() error {
@@ -32,3 +109,68 @@ func (u JWTUser) IsOK() error {
 
 	return nil
 }
+
+func JWTUserFromClaims(claims jwt.MapClaims) (JWTUser, error) {
+	expiredAt, err := claims.GetExpirationTime()
+	if err != nil {
+		return JWTUser{}, errors.Wrap(err, "cant get token expiration time")
+	}
+
+	issuedAt, err := claims.GetIssuedAt()
+	if err != nil {
+		return JWTUser{}, errors.Wrap(err, "cant get token issued at time")
+	}
+
+	audience, err := claims.GetAudience()
+	if err != nil || len(audience) != 1 || audience[0] != AccessTokenAudience {
+		return JWTUser{}, ErrNotAccessToken
+	}
+
+	sub, err := claims.GetSubject()
+	if err != nil {
+		return JWTUser{}, errors.Wrap(err, "cant get token subject")
+	}
+
+	userID, err := strconv.ParseInt(sub, 10, 32)
+	if err != nil {
+		return JWTUser{}, errors.Wrap(err, "cant parse user id")
+	}
+
+	jti, _ := claims["jti"].(string)
+	sid, _ := claims["sid"].(string)
+	csrf, _ := claims["csrf"].(string)
+	roles, _ := claims["roles"].([]interface{})
+	act, _ := claims["act"].(map[string]interface{})
+
+	jUser := JWTUser{
+		ID:        jti,
+		UserID:    int32(userID),
+		SessionID: sid,
+		CSRFHash:  csrf,
+	}
+
+	if actorSub, ok := act["sub"].(string); ok {
+		actorID, err := strconv.ParseInt(actorSub, 10, 32)
+		if err != nil || actorID <= 0 {
+			return JWTUser{}, errors.Wrap(ErrInvalidUserID, "invalid act claim")
+		}
+
+		jUser.ActorID = int32(actorID)
+	}
+
+	for _, role := range roles {
+		if name, ok := role.(string); ok {
+			jUser.Roles = append(jUser.Roles, name)
+		}
+	}
+
+	if expiredAt != nil {
+		jUser.ExpiredAt = expiredAt.Time
+	}
+
+	if issuedAt != nil {
+		jUser.IssuedAt = issuedAt.Time
+	}
+
+	return jUser, nil
+}
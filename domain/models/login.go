@@ -0,0 +1,37 @@
+package models
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"time"
+)
+
+var (
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrLoginLocked        = errors.New("too many failed login attempts")
+)
+
+// ClientInfo сведения о клиенте, от имени которого выполняется запрос
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+type LoginAttempts struct {
+	Failures     int
+	LastFailedAt time.Time
+	LockedUntil  time.Time
+}
+
+// LoginLockedError сообщает, через сколько можно повторить попытку входа
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e LoginLockedError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", ErrLoginLocked, e.RetryAfter)
+}
+
+func (e LoginLockedError) Is(target error) bool {
+	return target == ErrLoginLocked
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"github.com/pkg/errors"
+	"time"
+)
+
+// MFAAudience audience промежуточного токена, выдаваемого после пароля до ввода кода
+const MFAAudience = "twitter-bff:mfa"
+
+const (
+	MFAMethodTOTP         = "totp"
+	MFAMethodRecoveryCode = "recovery_code"
+)
+
+const FieldCode = "code"
+
+var (
+	ErrMFAAlreadyEnabled  = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnrolled     = errors.New("two-factor authentication is not enrolled")
+	ErrInvalidMFAToken    = errors.New("invalid or expired mfa token")
+	ErrInvalidMFACode     = errors.New("invalid code")
+	ErrMFAChallengeReused = errors.New("mfa token has already been used")
+)
+
+// TOTPFactor второй фактор пользователя. Пока Confirmed false, фактор не участвует во входе.
+type TOTPFactor struct {
+	UserID    int32
+	Secret    string
+	Confirmed bool
+	// RecoveryCodeHashes хэши одноразовых кодов восстановления
+	RecoveryCodeHashes []string
+	// LastUsedStep номер последнего принятого 30-секундного интервала, защищает от повтора кода
+	LastUsedStep int64
+}
+
+type TOTPEnrollment struct {
+	Secret string
+	URI    string
+	QRCode []byte
+}
+
+// LoginResult либо сразу пара токенов, либо промежуточный токен, если включен второй фактор
+type LoginResult struct {
+	Token        JWTToken
+	MFARequired  bool
+	MFAToken     string
+	MFAExpiredAt time.Time
+}
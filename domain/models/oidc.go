@@ -0,0 +1,41 @@
+package models
+
+import (
+	"github.com/pkg/errors"
+	"time"
+)
+
+const OIDCStateCookieName = "oidc-state"
+
+var (
+	ErrUnknownOIDCProvider = errors.New("unknown identity provider")
+	ErrInvalidOIDCState    = errors.New("invalid or expired oidc state")
+	ErrInvalidIDToken      = errors.New("invalid id token")
+	// ErrOIDCEmailNotVerified провайдер не подтвердил email. По такому адресу нельзя ни найти аккаунт,
+	// ни завести новый: иначе любой, кто заведет у провайдера чужой адрес, войдет в аккаунт или займет его.
+	ErrOIDCEmailNotVerified = errors.New("email is not verified by identity provider")
+	// ErrOIDCAccountNotVerified аккаунт с таким email есть, но владелец не подтверждал у нас этот адрес.
+	// Аккаунт мог завести кто угодно, знающий адрес, поэтому привязка ждет, пока email подтвердят по письму.
+	ErrOIDCAccountNotVerified = errors.New("account email is not verified")
+)
+
+// OIDCAuthRequest параметры начатого входа через провайдера, живут до возврата пользователя
+type OIDCAuthRequest struct {
+	// StateHash хэш параметра state, сам state есть только в браузере
+	StateHash    string
+	Provider     string
+	Nonce        string
+	CodeVerifier string
+	ExpiredAt    time.Time
+}
+
+// OIDCIdentity проверенные данные из ID токена
+type OIDCIdentity struct {
+	Provider          string
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	PreferredUsername string
+	Nonce             string
+}
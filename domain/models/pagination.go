@@ -0,0 +1,94 @@
+package models
+
+import (
+	"encoding/base64"
+	"fmt"
+	"time"
+)
+
+const (
+	FieldCursor = "cursor"
+	FieldLimit  = "limit"
+)
+
+// PageRequest параметры страницы ленты. Пустой Cursor - первая страница, Limit 0 - размер по умолчанию.
+type PageRequest struct {
+	Cursor string
+	Limit  int32
+}
+
+// PostCursor позиция в ленте: последний пост предыдущей страницы.
+// Лента упорядочена по (CreatedAt, ID) по убыванию, ID различает посты с одинаковым временем.
+type PostCursor struct {
+	CreatedAt time.Time
+	ID        int32
+}
+
+func (c PostCursor) IsZero() bool {
+	return c.ID == 0 && c.CreatedAt.IsZero()
+}
+
+// Before сообщает, что пост идет в ленте после курсора
+func (c PostCursor) Before(post Post) bool {
+	return c.Precedes(CursorOf(post))
+}
+
+// Precedes сообщает, что позиция other идет в ленте после курсора
+func (c PostCursor) Precedes(other PostCursor) bool {
+	if c.IsZero() {
+		return true
+	}
+
+	if !other.CreatedAt.Equal(c.CreatedAt) {
+		return other.CreatedAt.Before(c.CreatedAt)
+	}
+
+	return other.ID < c.ID
+}
+
+// Encode возвращает непрозрачное для клиента значение курсора
+func (c PostCursor) Encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%d", c.CreatedAt.UnixNano(), c.ID)))
+}
+
+func CursorOf(post Post) PostCursor {
+	return PostCursor{CreatedAt: post.CreatedAt, ID: post.ID}
+}
+
+func DecodePostCursor(value string) (PostCursor, error) {
+	if len(value) == 0 {
+		return PostCursor{}, nil
+	}
+
+	invalid := ValidationError{Fields: []FieldError{{
+		Field:   FieldCursor,
+		Code:    ErrCodeInvalid,
+		Message: "cursor is malformed",
+	}}}
+
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return PostCursor{}, invalid
+	}
+
+	var (
+		nanos int64
+		id    int32
+	)
+
+	_, err = fmt.Sscanf(string(raw), "%d:%d", &nanos, &id)
+	if err != nil || id == 0 {
+		return PostCursor{}, invalid
+	}
+
+	return PostCursor{CreatedAt: time.Unix(0, nanos), ID: id}, nil
+}
+
+// PostsPage страница постов пользователя. NextCursor пуст на последней странице.
+type PostsPage struct {
+	Posts      []Post
+	NextCursor string
+	// Truncated страница последняя не потому, что посты кончились: более старые не поместились
+	// в окно, которое BFF готов запросить у twitter-posts
+	Truncated bool
+}
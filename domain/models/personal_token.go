@@ -0,0 +1,45 @@
+package models
+
+import (
+	"github.com/pkg/errors"
+	"time"
+)
+
+// PersonalTokenPrefix отличает персональные токены от JWT в заголовке Authorization
+const PersonalTokenPrefix = "tbp_"
+
+const (
+	FieldName      = "name"
+	FieldScopes    = "scopes"
+	FieldExpiresAt = "expiresAt"
+)
+
+const (
+	ScopeRead         = "read"
+	ScopePostsWrite   = "posts:write"
+	ScopeFollowsWrite = "follows:write"
+	ScopeLikesWrite   = "likes:write"
+)
+
+// Scopes все права, которые можно выдать персональному токену
+var Scopes = []string{ScopeRead, ScopePostsWrite, ScopeFollowsWrite, ScopeLikesWrite}
+
+var ErrInvalidPersonalToken = errors.New("invalid personal access token")
+
+// PersonalToken токен для скриптов и интеграций. Хранится только хэш, сам токен
+// показывается один раз при создании.
+type PersonalToken struct {
+	ID     string
+	UserID int32
+	Name   string
+	Hash   string
+	Scopes []string
+	// ExpiredAt нулевое значение - бессрочный токен
+	ExpiredAt  time.Time
+	CreatedAt  time.Time
+	LastUsedAt time.Time
+}
+
+func (t PersonalToken) IsExpired(now time.Time) bool {
+	return !t.ExpiredAt.IsZero() && now.After(t.ExpiredAt)
+}
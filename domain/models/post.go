@@ -2,6 +2,11 @@ package models
 
 import "time"
 
+const (
+	FieldInReplyToPostID = "inReplyToPostId"
+	FieldQuotedPostID    = "quotedPostId"
+)
+
 type Post struct {
 	ID                int32
 	Body              string
@@ -12,4 +17,13 @@ type Post struct {
 	LikeCount         int32
 	IsCurrentUserLike bool
 	Comments          []Comment
+	// InReplyToPostID пост, на который отвечает этот, 0 - не ответ
+	InReplyToPostID int32
+	ReplyCount      int32
+	// QuotedPostID цитируемый пост, 0 - не цитата
+	QuotedPostID int32
+	// QuotedPost цитируемый пост с автором, nil - если он удален или не загружался
+	QuotedPost            *Post
+	RepostCount           int32
+	IsCurrentUserReposted bool
 }
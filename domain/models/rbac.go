@@ -0,0 +1,39 @@
+package models
+
+import "github.com/pkg/errors"
+
+const (
+	RoleAdmin     = "admin"
+	RoleModerator = "moderator"
+)
+
+const (
+	PermissionUsersRead        = "users:read"
+	PermissionUsersLogout      = "users:logout"
+	PermissionPostingRestrict  = "posting:restrict"
+	PermissionUsersImpersonate = "users:impersonate"
+)
+
+// RolePermissions права ролей. Роли попадают в access токен, права вычисляются по ним
+// при каждом запросе, поэтому изменение этой таблицы действует сразу.
+var RolePermissions = map[string][]string{
+	RoleAdmin: {
+		PermissionUsersRead,
+		PermissionUsersLogout,
+		PermissionPostingRestrict,
+		PermissionUsersImpersonate,
+	},
+	RoleModerator: {PermissionUsersRead, PermissionPostingRestrict},
+}
+
+var ErrPostingDisabled = errors.New("posting is disabled for this account")
+
+// AdminAccount сведения об аккаунте для администратора, включая состояние, которое хранит BFF
+type AdminAccount struct {
+	User            User
+	Roles           []string
+	EmailVerified   bool
+	MFAEnabled      bool
+	PostingDisabled bool
+	Sessions        []Session
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// Repost репост поста без собственного текста. Хранится в BFF, в twitter-posts его нет.
+type Repost struct {
+	ID        int32
+	UserID    int32
+	PostID    int32
+	CreatedAt time.Time
+}
+
+// Cursor позиция репоста в ленте. Чтобы ID репоста не совпадал с ID постов, в курсоре он отрицательный.
+func (r Repost) Cursor() PostCursor {
+	return PostCursor{CreatedAt: r.CreatedAt, ID: -r.ID}
+}
+
+// FeedItem элемент ленты: пост автора из подписок или чужой пост, который они репостнули
+type FeedItem struct {
+	Post Post
+	// Repost заполнен, если пост попал в ленту репостом
+	Repost     *Repost
+	RepostedBy User
+}
+
+// Cursor позиция элемента в ленте. Репост стоит по времени репоста.
+func (i FeedItem) Cursor() PostCursor {
+	if i.Repost != nil {
+		return i.Repost.Cursor()
+	}
+
+	return CursorOf(i.Post)
+}
+
+// FeedPage страница ленты. NextCursor пуст на последней странице, Truncated как у PostsPage.
+type FeedPage struct {
+	Items      []FeedItem
+	NextCursor string
+	Truncated  bool
+}
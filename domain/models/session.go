@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// Session вход пользователя на одном устройстве. ID совпадает с семейством refresh токенов
+// и передается в access токене в claim sid: отзыв сессии отзывает и ее токены.
+type Session struct {
+	ID         string
+	UserID     int32
+	UserAgent  string
+	IP         string
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	// ExpiredAt истечение последнего refresh токена сессии, продлевается при обновлении
+	ExpiredAt time.Time
+}
@@ -0,0 +1,17 @@
+package models
+
+// Thread ветка обсуждения вокруг поста: цепочка предков от корня и страница ответов
+type Thread struct {
+	Ancestors []Post
+	Post      Post
+	Replies   []ThreadReply
+	// NextCursor курсор следующей страницы прямых ответов, пуст на последней
+	NextCursor string
+}
+
+// ThreadReply ответ с вложенными ответами. Вложенные ответы ограничены по глубине и количеству,
+// полностью их показывает ветка самого ответа.
+type ThreadReply struct {
+	Post    Post
+	Replies []ThreadReply
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"strings"
+)
+
+const (
+	FieldPassword = "password"
+	FieldToken    = "token"
+)
+
+const (
+	ErrCodeTooShort         = "too_short"
+	ErrCodeTooLong          = "too_long"
+	ErrCodeTooSimple        = "too_simple"
+	ErrCodeContainsUsername = "contains_username"
+	ErrCodeContainsEmail    = "contains_email"
+	ErrCodeBreached         = "breached"
+	ErrCodeInvalid          = "invalid"
+)
+
+// FieldError ошибка конкретного поля запроса. Code стабилен и предназначен для фронтенда,
+// Message - человекочитаемое описание.
+type FieldError struct {
+	Field   string
+	Code    string
+	Message string
+}
+
+// ValidationError набор ошибок по полям, отдается клиенту как 422
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		messages = append(messages, f.Field+": "+f.Message)
+	}
+
+	return ErrInvalidArgument.Error() + ": " + strings.Join(messages, "; ")
+}
+
+func (e ValidationError) Is(target error) bool {
+	return target == ErrInvalidArgument
+}
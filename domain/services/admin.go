@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"twitter-bff/domain/models"
+)
+
+const maxAdminUsersLimit = 500
+
+// RoleRepository роли пользователя. Пользователь без ролей - обычный пользователь.
+type RoleRepository interface {
+	Roles(ctx context.Context, userID int32) ([]string, error)
+}
+
+type AdminUsersRepository interface {
+	FetchUsersByIDs(ctx context.Context, ids []int32) (map[int32]models.User, error)
+	NewUsers(ctx context.Context, limit int32) ([]models.User, error)
+}
+
+type SessionTerminator interface {
+	LogoutEverywhere(ctx context.Context, userID int32) error
+}
+
+type AdminService struct {
+	users        AdminUsersRepository
+	roles        RoleRepository
+	verification EmailVerificationRepository
+	mfa          MFAStatusRepository
+	sessions     SessionRepository
+	restrictions PostingRestrictionRepository
+	terminator   SessionTerminator
+}
+
+// ListUsers последние зарегистрированные пользователи. Другого списка twitter-users не отдает.
+func (s *AdminService) ListUsers(ctx context.Context, limit int32) ([]models.User, error) {
+	if limit <= 0 {
+		limit = defaultUsersLimit
+	}
+
+	if limit > maxAdminUsersLimit {
+		limit = maxAdminUsersLimit
+	}
+
+	users, err := s.users.NewUsers(ctx, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "users repo err")
+	}
+
+	return users, nil
+}
+
+func (s *AdminService) Account(ctx context.Context, userID int32) (models.AdminAccount, error) {
+	usersByID, err := s.users.FetchUsersByIDs(ctx, []int32{userID})
+	if err != nil {
+		return models.AdminAccount{}, errors.Wrap(err, "users repo err")
+	}
+
+	user, ok := usersByID[userID]
+	if !ok {
+		return models.AdminAccount{}, errors.Wrap(models.ErrNotFound, "user not found")
+	}
+
+	roles, err := s.roles.Roles(ctx, userID)
+	if err != nil {
+		return models.AdminAccount{}, errors.Wrap(err, "role repo err")
+	}
+
+	verified, err := s.verification.IsVerified(ctx, userID, user.Email)
+	if err != nil {
+		return models.AdminAccount{}, errors.Wrap(err, "verification repo err")
+	}
+
+	mfaEnabled, err := s.mfa.IsEnabled(ctx, userID)
+	if err != nil {
+		return models.AdminAccount{}, errors.Wrap(err, "mfa repo err")
+	}
+
+	postingDisabled, err := s.restrictions.IsPostingDisabled(ctx, userID)
+	if err != nil {
+		return models.AdminAccount{}, errors.Wrap(err, "posting restriction repo err")
+	}
+
+	sessions, err := s.sessions.ListSessions(ctx, userID)
+	if err != nil {
+		return models.AdminAccount{}, errors.Wrap(err, "session repo err")
+	}
+
+	user.PasswordHash = ""
+
+	return models.AdminAccount{
+		User:            user,
+		Roles:           roles,
+		EmailVerified:   verified,
+		MFAEnabled:      mfaEnabled,
+		PostingDisabled: postingDisabled,
+		Sessions:        sessions,
+	}, nil
+}
+
+// ForceLogout завершает все сессии пользователя и отзывает его персональные токены
+func (s *AdminService) ForceLogout(ctx context.Context, userID int32) error {
+	return s.terminator.LogoutEverywhere(ctx, userID)
+}
+
+func (s *AdminService) SetPostingDisabled(ctx context.Context, userID int32, disabled bool) error {
+	usersByID, err := s.users.FetchUsersByIDs(ctx, []int32{userID})
+	if err != nil {
+		return errors.Wrap(err, "users repo err")
+	}
+
+	if _, ok := usersByID[userID]; !ok {
+		return errors.Wrap(models.ErrNotFound, "user not found")
+	}
+
+	err = s.restrictions.SetPostingDisabled(ctx, userID, disabled)
+	if err != nil {
+		return errors.Wrap(err, "posting restriction repo err")
+	}
+
+	return nil
+}
+
+func NewAdminService(
+	users AdminUsersRepository,
+	roles RoleRepository,
+	verification EmailVerificationRepository,
+	mfa MFAStatusRepository,
+	sessions SessionRepository,
+	restrictions PostingRestrictionRepository,
+	terminator SessionTerminator,
+) *AdminService {
+	return &AdminService{
+		users:        users,
+		roles:        roles,
+		verification: verification,
+		mfa:          mfa,
+		sessions:     sessions,
+		restrictions: restrictions,
+		terminator:   terminator,
+	}
+}
@@ -10,19 +10,43 @@ type CreateRepository interface {
 	Create(ctx context.Context, name string, passwordHash string, username string, email string) (models.User, error)
 }
 
+type VerificationSender interface {
+	SendVerification(ctx context.Context, user models.User) error
+}
+
 type CreateUserService struct {
-	repo CreateRepository
+	repo         CreateRepository
+	policy       *PasswordPolicy
+	verification VerificationSender
 }
 
 func (s *CreateUserService) Create(ctx context.Context, name, password, username, email string) (models.User, error) {
+	err := s.policy.Validate(ctx, password, username, email)
+	if err != nil {
+		return models.User{}, err
+	}
+
 	hash, err := helpers.GenerateHash(password)
 	if err != nil {
 		return models.User{}, err
 	}
 
-	return s.repo.Create(ctx, name, hash, username, email)
+	user, err := s.repo.Create(ctx, name, hash, username, email)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	// письмо можно запросить повторно через /v1/verify-email/resend,
+	// поэтому ошибка отправки не отменяет регистрацию
+	_ = s.verification.SendVerification(ctx, user)
+
+	return user, nil
 }
 
-func NewCreateUserService(repo CreateRepository) *CreateUserService {
-	return &CreateUserService{repo: repo}
+func NewCreateUserService(repo CreateRepository, policy *PasswordPolicy, verification VerificationSender) *CreateUserService {
+	return &CreateUserService{
+		repo:         repo,
+		policy:       policy,
+		verification: verification,
+	}
 }
@@ -0,0 +1,276 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pkg/errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"twitter-bff/domain/models"
+)
+
+const (
+	defaultEmailVerificationTTL = 24 * time.Hour
+	defaultResendInterval       = time.Minute
+	defaultMaxResends           = 5
+	defaultResendWindow         = time.Hour
+)
+
+type Mailer interface {
+	Send(ctx context.Context, mail models.Mail) error
+}
+
+type EmailVerificationRepository interface {
+	MarkVerified(ctx context.Context, userID int32, email string) error
+	// IsVerified true, только если подтвержден именно этот email пользователя
+	IsVerified(ctx context.Context, userID int32, email string) (bool, error)
+}
+
+type EmailVerificationUsersRepository interface {
+	FetchUsersByIDs(ctx context.Context, ids []int32) (map[int32]models.User, error)
+}
+
+type TokenParser interface {
+	Parse(rawToken, audience string) (jwt.MapClaims, error)
+}
+
+// ResendAttemptsRepository счетчики отправленных писем подтверждения. Отдельное хранилище, не общее
+// со счетчиками входа: очистка по окну входа стирала бы счетчики с более длинным окном отправки.
+type ResendAttemptsRepository interface {
+	// Increment атомарно учитывает отправку и выставляет блокировку на lockFor(sent).
+	// Если ключ уже заблокирован, отправка не учитывается и возвращается false.
+	Increment(
+		ctx context.Context,
+		key string,
+		window time.Duration,
+		lockFor func(sent int) time.Duration,
+	) (models.LoginAttempts, bool, error)
+}
+
+// EmailVerificationChecker нужен сервисам, которые закрыты для пользователей без подтвержденного email
+type EmailVerificationChecker interface {
+	CheckVerified(ctx context.Context, userID int32) error
+}
+
+type EmailVerificationConfig struct {
+	TokenTTL time.Duration
+	// URL страницы фронтенда, токен добавляется параметром token
+	URL string
+	// ResendInterval минимальный интервал между письмами одному пользователю
+	ResendInterval time.Duration
+	// MaxResends сколько писем можно отправить за ResendWindow
+	MaxResends   int
+	ResendWindow time.Duration
+	// RestrictUnverified запрещает пользователям без подтвержденного email публиковать посты
+	RestrictUnverified bool
+}
+
+type EmailVerificationService struct {
+	repo         EmailVerificationRepository
+	usersRepo    EmailVerificationUsersRepository
+	attemptsRepo ResendAttemptsRepository
+	mailer       Mailer
+	signer       TokenSigner
+	parser       TokenParser
+	config       EmailVerificationConfig
+}
+
+// SendVerification отправляет письмо со ссылкой подтверждения. Ссылка содержит подписанный токен
+// с email, поэтому хранить токены не нужно, а после смены адреса старая ссылка перестает работать.
+func (s *EmailVerificationService) SendVerification(ctx context.Context, user models.User) error {
+	now := time.Now()
+
+	token, err := s.signer.Sign(jwt.MapClaims{
+		"aud":   models.EmailVerificationAudience,
+		"sub":   fmt.Sprint(user.ID),
+		"email": user.Email,
+		"iat":   now.Unix(),
+		"exp":   now.Add(s.config.TokenTTL).Unix(),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to sign verification token")
+	}
+
+	err = s.mailer.Send(ctx, models.Mail{
+		To:      user.Email,
+		Subject: "Confirm your email",
+		Body: fmt.Sprintf(
+			"Welcome, %s!\n\n"+
+				"Follow the link to confirm your email, it is valid for %s:\n%s\n\n"+
+				"If you didn't create an account, ignore this email.\n",
+			user.Name, s.config.TokenTTL, withToken(s.config.URL, token),
+		),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to send verification mail")
+	}
+
+	return nil
+}
+
+// Resend повторно отправляет письмо не чаще ResendInterval и не больше MaxResends за ResendWindow
+func (s *EmailVerificationService) Resend(ctx context.Context, userID int32) error {
+	user, err := s.user(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	verified, err := s.repo.IsVerified(ctx, user.ID, user.Email)
+	if err != nil {
+		return errors.Wrap(err, "email verification repo err")
+	}
+
+	if verified {
+		return models.ErrEmailAlreadyVerified
+	}
+
+	key := "verify-email:" + strconv.Itoa(int(user.ID))
+	now := time.Now()
+
+	attempts, reserved, err := s.attemptsRepo.Increment(ctx, key, s.config.ResendWindow, func(sent int) time.Duration {
+		if sent >= s.config.MaxResends {
+			return s.config.ResendWindow
+		}
+
+		return s.config.ResendInterval
+	})
+	if err != nil {
+		return errors.Wrap(err, "attempts repo err")
+	}
+
+	if !reserved {
+		wait := attempts.LockedUntil.Sub(now)
+		return models.RateLimitedError{RetryAfter: (wait + time.Second - 1).Truncate(time.Second)}
+	}
+
+	return s.SendVerification(ctx, user)
+}
+
+func (s *EmailVerificationService) Verify(ctx context.Context, token string) error {
+	claims, err := s.parser.Parse(token, models.EmailVerificationAudience)
+	if err != nil {
+		return invalidVerificationToken()
+	}
+
+	sub, _ := claims.GetSubject()
+	email, _ := claims["email"].(string)
+
+	userID, err := strconv.ParseInt(sub, 10, 32)
+	if err != nil || len(email) == 0 {
+		return invalidVerificationToken()
+	}
+
+	user, err := s.user(ctx, int32(userID))
+	if errors.Is(err, models.ErrNotFound) {
+		return invalidVerificationToken()
+	}
+	if err != nil {
+		return err
+	}
+
+	// адрес сменился после отправки письма
+	if user.Email != email {
+		return invalidVerificationToken()
+	}
+
+	err = s.repo.MarkVerified(ctx, user.ID, user.Email)
+	if err != nil {
+		return errors.Wrap(err, "email verification repo err")
+	}
+
+	return nil
+}
+
+func (s *EmailVerificationService) CheckVerified(ctx context.Context, userID int32) error {
+	if !s.config.RestrictUnverified {
+		return nil
+	}
+
+	user, err := s.user(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	verified, err := s.repo.IsVerified(ctx, user.ID, user.Email)
+	if err != nil {
+		return errors.Wrap(err, "email verification repo err")
+	}
+
+	if !verified {
+		return models.ErrEmailNotVerified
+	}
+
+	return nil
+}
+
+func (s *EmailVerificationService) user(ctx context.Context, userID int32) (models.User, error) {
+	users, err := s.usersRepo.FetchUsersByIDs(ctx, []int32{userID})
+	if err != nil {
+		return models.User{}, errors.Wrap(err, "failed to fetch user")
+	}
+
+	user, ok := users[userID]
+	if !ok {
+		return models.User{}, models.ErrNotFound
+	}
+
+	return user, nil
+}
+
+func invalidVerificationToken() error {
+	return models.ValidationError{Fields: []models.FieldError{{
+		Field:   models.FieldToken,
+		Code:    models.ErrCodeInvalid,
+		Message: models.ErrInvalidOneTimeToken.Error(),
+	}}}
+}
+
+func withToken(rawURL, token string) string {
+	separator := "?"
+	if strings.Contains(rawURL, "?") {
+		separator = "&"
+	}
+
+	return rawURL + separator + "token=" + url.QueryEscape(token)
+}
+
+func NewEmailVerificationService(
+	repo EmailVerificationRepository,
+	usersRepo EmailVerificationUsersRepository,
+	attemptsRepo ResendAttemptsRepository,
+	mailer Mailer,
+	signer TokenSigner,
+	parser TokenParser,
+	c Config,
+) *EmailVerificationService {
+	config := c.EmailVerification
+
+	if config.TokenTTL == 0 {
+		config.TokenTTL = defaultEmailVerificationTTL
+	}
+
+	if config.ResendInterval == 0 {
+		config.ResendInterval = defaultResendInterval
+	}
+
+	if config.MaxResends == 0 {
+		config.MaxResends = defaultMaxResends
+	}
+
+	if config.ResendWindow == 0 {
+		config.ResendWindow = defaultResendWindow
+	}
+
+	return &EmailVerificationService{
+		repo:         repo,
+		usersRepo:    usersRepo,
+		attemptsRepo: attemptsRepo,
+		mailer:       mailer,
+		signer:       signer,
+		parser:       parser,
+		config:       config,
+	}
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"twitter-bff/domain/models"
+)
+
+const (
+	defaultHashtagsLimit = 10
+	maxHashtagsLimit     = 50
+)
+
+// HashtagPosts посты с тегом от новых к старым. Теги знает только индекс BFF,
+// поэтому страница собирается из позиций индекса, а посты загружаются одним запросом.
+func (s *PostsService) HashtagPosts(
+	ctx context.Context,
+	tag string,
+	userID int32,
+	page models.PageRequest,
+) (models.PostsPage, error) {
+	tag, err := models.NormalizeHashtag(tag)
+	if err != nil {
+		return models.PostsPage{}, err
+	}
+
+	cursor, err := models.DecodePostCursor(page.Cursor)
+	if err != nil {
+		return models.PostsPage{}, err
+	}
+
+	limit := pageLimit(page)
+
+	positions, err := s.hashtags.PostsByHashtag(ctx, tag, cursor, limit+1)
+	if err != nil {
+		return models.PostsPage{}, errors.Wrap(err, "hashtag repo err")
+	}
+
+	result := models.PostsPage{Posts: make([]models.Post, 0, min(int32(len(positions)), limit))}
+	if int32(len(positions)) > limit {
+		positions = positions[:limit]
+		result.NextCursor = positions[limit-1].Encode()
+	}
+
+	postIDs := make([]int32, 0, len(positions))
+	for _, position := range positions {
+		postIDs = append(postIDs, position.ID)
+	}
+
+	posts, err := s.repo.PostsByIDs(ctx, postIDs, userID)
+	if err != nil {
+		return models.PostsPage{}, errors.Wrap(err, "posts repo err")
+	}
+
+	// удаленные в twitter-posts посты пропускаются, курсор страницы от этого не меняется
+	for _, postID := range postIDs {
+		if post, ok := posts[postID]; ok {
+			result.Posts = append(result.Posts, post)
+		}
+	}
+
+	err = s.hydratePosts(ctx, result.Posts, userID)
+	if err != nil {
+		return models.PostsPage{}, err
+	}
+
+	return result, nil
+}
+
+// SearchHashtags подсказки тегов по началу: сначала теги с большим числом постов
+func (s *PostsService) SearchHashtags(ctx context.Context, prefix string, limit int32) ([]models.Hashtag, error) {
+	prefix, err := models.NormalizeHashtagPrefix(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = defaultHashtagsLimit
+	}
+
+	hashtags, err := s.hashtags.SearchHashtags(ctx, prefix, min(limit, maxHashtagsLimit))
+	if err != nil {
+		return nil, errors.Wrap(err, "hashtag repo err")
+	}
+
+	return hashtags, nil
+}
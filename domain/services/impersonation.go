@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"strings"
+	"time"
+	"twitter-bff/domain/models"
+)
+
+const (
+	defaultImpersonationTTL = 15 * time.Minute
+	maxImpersonationReason  = 500
+)
+
+// AuditLogger журнал действий администраторов, отдельный от логов приложения
+type AuditLogger interface {
+	Record(ctx context.Context, entry models.AuditEntry) error
+}
+
+type ImpersonationConfig struct {
+	// TokenTTL сколько живет токен имперсонации. Продлить его нельзя, refresh токен не выдается.
+	TokenTTL time.Duration
+}
+
+type ImpersonationService struct {
+	users  AdminUsersRepository
+	signer TokenSigner
+	audit  AuditLogger
+	config Config
+}
+
+// Impersonate выпускает access токен пользователя userID для администратора actor.
+// Токен помечен claim act (RFC 8693) с администратором, не относится ни к одной сессии
+// и не содержит ролей, поэтому админские права через него недоступны.
+// Опасные операции для таких токенов закрывает ImpersonationMiddleware.
+func (s *ImpersonationService) Impersonate(
+	ctx context.Context,
+	actor models.JWTUser,
+	userID int32,
+	reason string,
+	client models.ClientInfo,
+) (models.JWTToken, error) {
+	if actor.IsImpersonated() {
+		return models.JWTToken{}, models.ErrImpersonationNotAllowed
+	}
+
+	reason = strings.TrimSpace(reason)
+	if len(reason) == 0 || len([]rune(reason)) > maxImpersonationReason {
+		return models.JWTToken{}, models.ValidationError{Fields: []models.FieldError{{
+			Field:   models.FieldReason,
+			Code:    models.ErrCodeInvalid,
+			Message: "reason must be between 1 and 500 characters",
+		}}}
+	}
+
+	if userID == actor.UserID {
+		return models.JWTToken{}, errors.Wrap(models.ErrInvalidArgument, "cannot impersonate yourself")
+	}
+
+	usersByID, err := s.users.FetchUsersByIDs(ctx, []int32{userID})
+	if err != nil {
+		return models.JWTToken{}, errors.Wrap(err, "users repo err")
+	}
+
+	if _, ok := usersByID[userID]; !ok {
+		return models.JWTToken{}, errors.Wrap(models.ErrNotFound, "user not found")
+	}
+
+	// запись о начале пишется до выпуска токена: токен без следа в журнале выдавать нельзя
+	err = s.audit.Record(ctx, models.AuditEntry{
+		Time:    time.Now(),
+		ActorID: actor.UserID,
+		UserID:  userID,
+		Action:  models.AuditActionImpersonationStart,
+		IP:      client.IP,
+		Reason:  reason,
+	})
+	if err != nil {
+		return models.JWTToken{}, errors.Wrap(err, "audit log err")
+	}
+
+	now := time.Now()
+	expiredAt := now.Add(s.config.Impersonation.TokenTTL)
+
+	signedString, err := s.signer.Sign(jwt.MapClaims{
+		"jti": uuid.NewString(),
+		"aud": models.AccessTokenAudience,
+		"sub": fmt.Sprint(userID),
+		"iat": now.Unix(),
+		"exp": expiredAt.Unix(),
+		"act": map[string]string{"sub": fmt.Sprint(actor.UserID)},
+	})
+	if err != nil {
+		return models.JWTToken{}, errors.Wrap(err, "failed to sign JWT")
+	}
+
+	return models.JWTToken{
+		UserID:          userID,
+		AccessToken:     signedString,
+		AccessExpiredAt: expiredAt,
+	}, nil
+}
+
+func NewImpersonationService(
+	users AdminUsersRepository,
+	signer TokenSigner,
+	audit AuditLogger,
+	c Config,
+) *ImpersonationService {
+	if c.Impersonation.TokenTTL == 0 {
+		c.Impersonation.TokenTTL = defaultImpersonationTTL
+	}
+
+	return &ImpersonationService{
+		users:  users,
+		signer: signer,
+		audit:  audit,
+		config: c,
+	}
+}
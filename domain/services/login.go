@@ -4,56 +4,325 @@ import (
 	"context"
 	"fmt"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/pkg/errors"
 	"time"
 	"twitter-bff/domain/models"
 	"twitter-bff/helpers"
 )
 
+const (
+	defaultAccessTokenTTL  = 15 * time.Minute
+	defaultRefreshTokenTTL = 30 * 24 * time.Hour
+)
+
 type LoginRepository interface {
 	FetchUserByEmail(ctx context.Context, email string) (models.User, error)
 }
 
+type RefreshTokenRepository interface {
+	Save(ctx context.Context, token models.RefreshToken) error
+	FetchByHash(ctx context.Context, hash string) (models.RefreshToken, error)
+	// MarkUsed атомарно помечает токен использованным, false - токен уже был использован
+	MarkUsed(ctx context.Context, hash string) (bool, error)
+	RevokeFamily(ctx context.Context, familyID string) error
+	RevokeUser(ctx context.Context, userID int32) error
+}
+
+type MFAStatusRepository interface {
+	IsEnabled(ctx context.Context, userID int32) (bool, error)
+}
+
+type TokenSigner interface {
+	Sign(claims jwt.Claims) (string, error)
+}
+
 type Config struct {
-	SecretKey string
+	AccessTokenTTL    time.Duration
+	RefreshTokenTTL   time.Duration
+	Throttle          LoginThrottleConfig
+	Password          PasswordPolicyConfig
+	EmailVerification EmailVerificationConfig
+	MFA               MFAConfig
+	OIDC              OIDCConfig
+	Impersonation     ImpersonationConfig
 }
 
 type LoginService struct {
-	repo   LoginRepository
-	config Config
+	repo        LoginRepository
+	refreshRepo RefreshTokenRepository
+	revocations RevocationRepository
+	sessions    SessionRepository
+	roles       RoleRepository
+	mfaRepo     MFAStatusRepository
+	signer      TokenSigner
+	throttle    *loginThrottle
+	dummyHash   string
+	config      Config
 }
 
-func (s *LoginService) Login(ctx context.Context, email, password string) (models.JWTToken, error) {
+// Login не различает несуществующий email и неверный пароль ни ответом, ни временем:
+// для неизвестного email пароль сравнивается с заранее посчитанным фиктивным хэшем.
+// Если у пользователя включен второй фактор, вместо пары токенов выдается промежуточный
+// mfa токен, который обменивается на пару токенов в MFAService.Verify.
+func (s *LoginService) Login(ctx context.Context, email, password string, client models.ClientInfo) (models.LoginResult, error) {
+	keys := s.throttle.keys(email, client)
+
+	err := s.throttle.reserve(ctx, keys)
+	if err != nil {
+		return models.LoginResult{}, err
+	}
+
 	user, err := s.repo.FetchUserByEmail(ctx, email)
+	if errors.Is(err, models.ErrNotFound) {
+		_ = helpers.CompareHashAndPassword(s.dummyHash, password)
+
+		return models.LoginResult{}, models.ErrInvalidCredentials
+	}
 	if err != nil {
-		return models.JWTToken{}, errors.Wrap(err, "failed to fetch user")
+		return models.LoginResult{}, errors.Wrap(err, "failed to fetch user")
 	}
 
 	err = helpers.CompareHashAndPassword(user.PasswordHash, password)
 	if err != nil {
-		return models.JWTToken{}, errors.Wrap(err, "failed to compare password hash")
+		return models.LoginResult{}, models.ErrInvalidCredentials
+	}
+
+	err = s.throttle.succeed(ctx, keys)
+	if err != nil {
+		return models.LoginResult{}, err
+	}
+
+	return s.StartSession(ctx, user.ID, client)
+}
+
+// StartSession завершает вход уже опознанного пользователя: выдает пару токенов
+// или mfa токен, если у пользователя включен второй фактор
+func (s *LoginService) StartSession(ctx context.Context, userID int32, client models.ClientInfo) (models.LoginResult, error) {
+	mfaEnabled, err := s.mfaRepo.IsEnabled(ctx, userID)
+	if err != nil {
+		return models.LoginResult{}, errors.Wrap(err, "mfa repo err")
+	}
+
+	if mfaEnabled {
+		return s.mfaChallenge(userID)
+	}
+
+	token, err := s.IssueSession(ctx, userID, client)
+	if err != nil {
+		return models.LoginResult{}, err
+	}
+
+	return models.LoginResult{Token: token}, nil
+}
+
+func (s *LoginService) mfaChallenge(userID int32) (models.LoginResult, error) {
+	now := time.Now()
+	expiredAt := now.Add(s.config.MFA.ChallengeTTL)
+
+	token, err := s.signer.Sign(jwt.MapClaims{
+		"aud": models.MFAAudience,
+		"jti": uuid.NewString(),
+		"sub": fmt.Sprint(userID),
+		"iat": now.Unix(),
+		"exp": expiredAt.Unix(),
+	})
+	if err != nil {
+		return models.LoginResult{}, errors.Wrap(err, "failed to sign mfa token")
+	}
+
+	return models.LoginResult{
+		MFARequired:  true,
+		MFAToken:     token,
+		MFAExpiredAt: expiredAt,
+	}, nil
+}
+
+// IssueSession начинает новую сессию пользователя с новым семейством refresh токенов
+func (s *LoginService) IssueSession(ctx context.Context, userID int32, client models.ClientInfo) (models.JWTToken, error) {
+	sessionID := uuid.NewString()
+
+	token, err := s.issue(ctx, userID, sessionID)
+	if err != nil {
+		return models.JWTToken{}, err
+	}
+
+	now := time.Now()
+
+	err = s.sessions.SaveSession(ctx, models.Session{
+		ID:         sessionID,
+		UserID:     userID,
+		UserAgent:  client.UserAgent,
+		IP:         client.IP,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiredAt:  token.RefreshExpiredAt,
+	})
+	if err != nil {
+		return models.JWTToken{}, errors.Wrap(err, "failed to save session")
+	}
+
+	return token, nil
+}
+
+// Refresh обменивает refresh токен на новую пару токенов. Повторное предъявление
+// уже использованного токена означает его утечку, поэтому отзывается все семейство
+// вместе с access токенами сессии, как при выходе.
+func (s *LoginService) Refresh(ctx context.Context, refreshToken string, client models.ClientInfo) (models.JWTToken, error) {
+	if len(refreshToken) == 0 {
+		return models.JWTToken{}, models.ErrInvalidRefreshToken
+	}
+
+	hash := helpers.HashToken(refreshToken)
+
+	stored, err := s.refreshRepo.FetchByHash(ctx, hash)
+	if errors.Is(err, models.ErrNotFound) {
+		return models.JWTToken{}, models.ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return models.JWTToken{}, errors.Wrap(err, "refresh token repo err")
+	}
+
+	if time.Now().After(stored.ExpiredAt) {
+		return models.JWTToken{}, models.ErrInvalidRefreshToken
+	}
+
+	ok, err := s.refreshRepo.MarkUsed(ctx, hash)
+	if err != nil {
+		return models.JWTToken{}, errors.Wrap(err, "refresh token repo err")
+	}
+
+	if !ok {
+		err = s.refreshRepo.RevokeFamily(ctx, stored.FamilyID)
+		if err != nil {
+			return models.JWTToken{}, errors.Wrap(err, "failed to revoke token family")
+		}
+
+		err = s.revocations.RevokeSession(ctx, stored.FamilyID, time.Now().Add(s.config.AccessTokenTTL))
+		if err != nil {
+			return models.JWTToken{}, errors.Wrap(err, "revocation repo err")
+		}
+
+		return models.JWTToken{}, models.ErrRefreshTokenReused
+	}
+
+	token, err := s.issue(ctx, stored.UserID, stored.FamilyID)
+	if err != nil {
+		return models.JWTToken{}, err
+	}
+
+	err = s.sessions.TouchSession(ctx, stored.FamilyID, client, time.Now(), token.RefreshExpiredAt)
+	if err != nil {
+		return models.JWTToken{}, errors.Wrap(err, "failed to update session")
+	}
+
+	return token, nil
+}
+
+func (s *LoginService) issue(ctx context.Context, userID int32, familyID string) (models.JWTToken, error) {
+	now := time.Now()
+	accessExpiredAt := now.Add(s.config.AccessTokenTTL)
+
+	// CSRF токен привязан к access токену: в нем хранится только хэш, поэтому подставленная
+	// злоумышленником cookie csrf-token не подойдет к чужому access токену
+	csrfToken, err := helpers.GenerateRandomToken()
+	if err != nil {
+		return models.JWTToken{}, errors.Wrap(err, "failed to generate csrf token")
 	}
 
 	// Генерируем полезные данные, которые будут храниться в токене
 	payload := jwt.MapClaims{
-		"sub": fmt.Sprint(user.ID),
-		"exp": time.Now().Add(time.Hour * 72).Unix(),
+		"jti":  uuid.NewString(),
+		"aud":  models.AccessTokenAudience,
+		"sub":  fmt.Sprint(userID),
+		"iat":  now.Unix(),
+		"exp":  accessExpiredAt.Unix(),
+		"sid":  familyID,
+		"csrf": helpers.HashToken(csrfToken),
+	}
+
+	// роли перечитываются при каждом выпуске, изменения доходят до пользователя с обновлением токена
+	roles, err := s.roles.Roles(ctx, userID)
+	if err != nil {
+		return models.JWTToken{}, errors.Wrap(err, "role repo err")
 	}
 
-	// Создаем новый JWT-токен и подписываем его по алгоритму HS256
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, payload)
+	if len(roles) != 0 {
+		payload["roles"] = roles
+	}
 
-	signedString, err := token.SignedString([]byte(s.config.SecretKey))
+	// Подписываем токен активным ключом, kid попадает в заголовок токена
+	signedString, err := s.signer.Sign(payload)
 	if err != nil {
 		return models.JWTToken{}, errors.Wrap(err, "failed to sign JWT")
 	}
 
-	return models.JWTToken{Token: signedString}, nil
+	refreshToken, err := helpers.GenerateRandomToken()
+	if err != nil {
+		return models.JWTToken{}, errors.Wrap(err, "failed to generate refresh token")
+	}
+
+	refreshExpiredAt := now.Add(s.config.RefreshTokenTTL)
+
+	err = s.refreshRepo.Save(ctx, models.RefreshToken{
+		Hash:      helpers.HashToken(refreshToken),
+		FamilyID:  familyID,
+		UserID:    userID,
+		ExpiredAt: refreshExpiredAt,
+	})
+	if err != nil {
+		return models.JWTToken{}, errors.Wrap(err, "failed to save refresh token")
+	}
+
+	return models.JWTToken{
+		UserID:           userID,
+		SessionID:        familyID,
+		AccessToken:      signedString,
+		AccessExpiredAt:  accessExpiredAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiredAt: refreshExpiredAt,
+		CSRFToken:        csrfToken,
+	}, nil
 }
 
-func NewLoginService(repo LoginRepository, c Config) *LoginService {
-	return &LoginService{
-		repo:   repo,
-		config: c,
+func NewLoginService(
+	repo LoginRepository,
+	refreshRepo RefreshTokenRepository,
+	revocations RevocationRepository,
+	sessions SessionRepository,
+	roles RoleRepository,
+	attemptsRepo LoginAttemptsRepository,
+	mfaRepo MFAStatusRepository,
+	signer TokenSigner,
+	c Config,
+) (*LoginService, error) {
+	if c.AccessTokenTTL == 0 {
+		c.AccessTokenTTL = defaultAccessTokenTTL
+	}
+
+	if c.RefreshTokenTTL == 0 {
+		c.RefreshTokenTTL = defaultRefreshTokenTTL
+	}
+
+	if c.MFA.ChallengeTTL == 0 {
+		c.MFA.ChallengeTTL = defaultMFAChallengeTTL
 	}
+
+	dummyHash, err := helpers.GenerateHash(uuid.NewString())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate dummy hash")
+	}
+
+	return &LoginService{
+		repo:        repo,
+		refreshRepo: refreshRepo,
+		revocations: revocations,
+		sessions:    sessions,
+		roles:       roles,
+		mfaRepo:     mfaRepo,
+		signer:      signer,
+		throttle:    newLoginThrottle(attemptsRepo, c.Throttle),
+		dummyHash:   dummyHash,
+		config:      c,
+	}, nil
 }
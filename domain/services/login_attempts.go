@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"strconv"
+	"strings"
+	"time"
+	"twitter-bff/domain/models"
+)
+
+const (
+	defaultMaxAccountFailures = 5
+	defaultMaxIPFailures      = 50
+	defaultLockoutDuration    = 15 * time.Minute
+	defaultFailuresWindow     = 15 * time.Minute
+	defaultBackoffBase        = time.Second
+	defaultBackoffMax         = 30 * time.Second
+)
+
+type LoginAttemptsRepository interface {
+	// Increment атомарно учитывает попытку и выставляет блокировку на lockFor(failures).
+	// Если ключ уже заблокирован, попытка не учитывается и возвращается false.
+	Increment(
+		ctx context.Context,
+		key string,
+		window time.Duration,
+		lockFor func(failures int) time.Duration,
+	) (models.LoginAttempts, bool, error)
+	// Release вычитает одну попытку, не снимая блокировку
+	Release(ctx context.Context, key string) error
+	Reset(ctx context.Context, key string) error
+}
+
+type LoginThrottleConfig struct {
+	// MaxAccountFailures после стольких неудач подряд аккаунт блокируется на LockoutDuration
+	MaxAccountFailures int
+	// MaxIPFailures то же для IP адреса, порог выше из-за NAT
+	MaxIPFailures   int
+	LockoutDuration time.Duration
+	// FailuresWindow неудачи старше окна не учитываются
+	FailuresWindow time.Duration
+	// BackoffBase задержка после первой неудачи, дальше удваивается до BackoffMax
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+}
+
+type loginThrottle struct {
+	repo   LoginAttemptsRepository
+	config LoginThrottleConfig
+}
+
+type throttleKey struct {
+	key         string
+	maxFailures int
+	// shared ключ общий для многих пользователей (IP). Задержка между попытками мешала бы
+	// другим пользователям за тем же адресом, а успешный вход не должен обнулять счетчик,
+	// иначе перебор по многим аккаунтам с одного адреса сбрасывался бы одним своим входом.
+	shared bool
+}
+
+// keys IP идет первым: попытка, отклоненная по IP, не попадает в счетчик аккаунта
+func (t *loginThrottle) keys(email string, client models.ClientInfo) []throttleKey {
+	var keys []throttleKey
+	if len(client.IP) != 0 {
+		keys = append(keys, throttleKey{
+			key:         "ip:" + client.IP,
+			maxFailures: t.config.MaxIPFailures,
+			shared:      true,
+		})
+	}
+
+	return append(keys, throttleKey{
+		key:         "email:" + strings.ToLower(strings.TrimSpace(email)),
+		maxFailures: t.config.MaxAccountFailures,
+	})
+}
+
+// mfaKeys счетчик неверных кодов второго фактора. Пароль к этому моменту уже проверен,
+// поэтому задержки и блокировка как у аккаунта.
+func (t *loginThrottle) mfaKeys(userID int32) []throttleKey {
+	return []throttleKey{{
+		key:         "mfa:" + strconv.Itoa(int(userID)),
+		maxFailures: t.config.MaxAccountFailures,
+	}}
+}
+
+// reserve учитывает попытку до проверки пароля или кода и сразу выставляет блокировку, которую
+// получила бы неудача. Параллельные попытки с тем же ключом упираются в нее, а не проверяются
+// все разом мимо счетчика. Возвращает models.LoginLockedError, если ключ уже заблокирован.
+// Неудачная попытка так и остается учтенной, успешная снимается в succeed.
+func (t *loginThrottle) reserve(ctx context.Context, keys []throttleKey) error {
+	now := time.Now()
+
+	for _, k := range keys {
+		attempts, reserved, err := t.repo.Increment(ctx, k.key, t.config.FailuresWindow, func(failures int) time.Duration {
+			return t.lockFor(k, failures)
+		})
+		if err != nil {
+			return errors.Wrap(err, "login attempts repo err")
+		}
+
+		if !reserved {
+			// округляем вверх до секунды, в таком виде значение уйдет в заголовок Retry-After
+			retryAfter := attempts.LockedUntil.Sub(now)
+			return models.LoginLockedError{RetryAfter: (retryAfter + time.Second - 1).Truncate(time.Second)}
+		}
+	}
+
+	return nil
+}
+
+func (t *loginThrottle) lockFor(k throttleKey, failures int) time.Duration {
+	switch {
+	case failures >= k.maxFailures:
+		return t.config.LockoutDuration
+	case !k.shared:
+		return t.backoff(failures)
+	default:
+		return 0
+	}
+}
+
+// succeed сбрасывает счетчики пользователя вместе с блокировкой, из общих вычитает только эту попытку
+func (t *loginThrottle) succeed(ctx context.Context, keys []throttleKey) error {
+	for _, k := range keys {
+		var err error
+		if k.shared {
+			err = t.repo.Release(ctx, k.key)
+		} else {
+			err = t.repo.Reset(ctx, k.key)
+		}
+		if err != nil {
+			return errors.Wrap(err, "login attempts repo err")
+		}
+	}
+
+	return nil
+}
+
+func (t *loginThrottle) backoff(failures int) time.Duration {
+	backoff := t.config.BackoffBase
+	for i := 1; i < failures && backoff < t.config.BackoffMax; i++ {
+		backoff *= 2
+	}
+
+	return min(backoff, t.config.BackoffMax)
+}
+
+func newLoginThrottle(repo LoginAttemptsRepository, c LoginThrottleConfig) *loginThrottle {
+	if c.MaxAccountFailures == 0 {
+		c.MaxAccountFailures = defaultMaxAccountFailures
+	}
+
+	if c.MaxIPFailures == 0 {
+		c.MaxIPFailures = defaultMaxIPFailures
+	}
+
+	if c.LockoutDuration == 0 {
+		c.LockoutDuration = defaultLockoutDuration
+	}
+
+	if c.FailuresWindow == 0 {
+		c.FailuresWindow = defaultFailuresWindow
+	}
+
+	if c.BackoffBase == 0 {
+		c.BackoffBase = defaultBackoffBase
+	}
+
+	if c.BackoffMax == 0 {
+		c.BackoffMax = defaultBackoffMax
+	}
+
+	return &loginThrottle{
+		repo:   repo,
+		config: c,
+	}
+}
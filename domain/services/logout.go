@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"time"
+	"twitter-bff/domain/models"
+)
+
+type RevocationRepository interface {
+	RevokeToken(ctx context.Context, tokenID string, expiredAt time.Time) error
+	// RevokeUserTokens отзывает все токены пользователя, выпущенные до issuedBefore.
+	// Запись можно забыть после expiredAt, когда такие токены истекут сами.
+	RevokeUserTokens(ctx context.Context, userID int32, issuedBefore, expiredAt time.Time) error
+	// RevokeSession отзывает все access токены сессии, запись можно забыть после expiredAt
+	RevokeSession(ctx context.Context, sessionID string, expiredAt time.Time) error
+}
+
+type LogoutRefreshTokenRepository interface {
+	RevokeFamily(ctx context.Context, familyID string) error
+	RevokeUser(ctx context.Context, userID int32) error
+}
+
+type LogoutService struct {
+	repo           RevocationRepository
+	refreshRepo    LogoutRefreshTokenRepository
+	sessions       SessionRepository
+	personalTokens PersonalTokenRepository
+	config         Config
+}
+
+// Logout отзывает предъявленный access токен и завершает его сессию: refresh токены семейства
+// и остальные access токены сессии. Refresh токен не нужен - cookie с ним на этот путь не приходит.
+func (s *LogoutService) Logout(ctx context.Context, jUser models.JWTUser) error {
+	if len(jUser.ID) != 0 {
+		err := s.repo.RevokeToken(ctx, jUser.ID, jUser.ExpiredAt)
+		if err != nil {
+			return errors.Wrap(err, "revocation repo err")
+		}
+	}
+
+	if len(jUser.SessionID) == 0 {
+		return nil
+	}
+
+	err := s.refreshRepo.RevokeFamily(ctx, jUser.SessionID)
+	if err != nil {
+		return errors.Wrap(err, "refresh token repo err")
+	}
+
+	err = s.repo.RevokeSession(ctx, jUser.SessionID, time.Now().Add(s.config.AccessTokenTTL))
+	if err != nil {
+		return errors.Wrap(err, "revocation repo err")
+	}
+
+	return nil
+}
+
+// LogoutEverywhere завершает все сессии пользователя на всех устройствах и удаляет его персональные токены:
+// они не привязаны к сессиям и без этого пережили бы выход, хотя могли утечь вместе с ними
+func (s *LogoutService) LogoutEverywhere(ctx context.Context, userID int32) error {
+	if userID == 0 {
+		return errors.Wrap(models.ErrInvalidArgument, "invalid user id")
+	}
+
+	now := time.Now()
+
+	// iat хранится с точностью до секунды: без округления вниз токен, выпущенный в ту же секунду
+	// сразу после выхода, например новым входом, тоже оказался бы отозванным.
+	// Токены, выпущенные в эту секунду до выхода, отзываются вместе с их сессиями.
+	err := s.repo.RevokeUserTokens(ctx, userID, now.Truncate(time.Second), now.Add(s.config.AccessTokenTTL))
+	if err != nil {
+		return errors.Wrap(err, "revocation repo err")
+	}
+
+	sessions, err := s.sessions.ListSessions(ctx, userID)
+	if err != nil {
+		return errors.Wrap(err, "session repo err")
+	}
+
+	for _, session := range sessions {
+		err = s.repo.RevokeSession(ctx, session.ID, now.Add(s.config.AccessTokenTTL))
+		if err != nil {
+			return errors.Wrap(err, "revocation repo err")
+		}
+	}
+
+	err = s.refreshRepo.RevokeUser(ctx, userID)
+	if err != nil {
+		return errors.Wrap(err, "refresh token repo err")
+	}
+
+	err = s.personalTokens.DeleteByUser(ctx, userID)
+	if err != nil {
+		return errors.Wrap(err, "personal token repo err")
+	}
+
+	return nil
+}
+
+func NewLogoutService(
+	repo RevocationRepository,
+	refreshRepo LogoutRefreshTokenRepository,
+	sessions SessionRepository,
+	personalTokens PersonalTokenRepository,
+	c Config,
+) *LogoutService {
+	if c.AccessTokenTTL == 0 {
+		c.AccessTokenTTL = defaultAccessTokenTTL
+	}
+
+	return &LogoutService{
+		repo:           repo,
+		refreshRepo:    refreshRepo,
+		sessions:       sessions,
+		personalTokens: personalTokens,
+		config:         c,
+	}
+}
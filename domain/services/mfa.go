@@ -0,0 +1,364 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base32"
+	"github.com/pkg/errors"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	"image/png"
+	"strconv"
+	"strings"
+	"time"
+	"twitter-bff/domain/models"
+	"twitter-bff/helpers"
+)
+
+const (
+	defaultMFAIssuer       = "twitter-bff"
+	defaultMFAChallengeTTL = 5 * time.Minute
+
+	totpPeriod = 30
+	// totpSkew сколько соседних интервалов принимается, чтобы пережить расхождение часов
+	totpSkew   = 1
+	totpDigits = otp.DigitsSix
+	qrCodeSize = 256
+
+	recoveryCodesCount  = 10
+	recoveryCodeLength  = 10
+	recoveryCodeDivider = "-"
+)
+
+type TOTPRepository interface {
+	Fetch(ctx context.Context, userID int32) (models.TOTPFactor, error)
+	Save(ctx context.Context, factor models.TOTPFactor) error
+	Delete(ctx context.Context, userID int32) error
+	// UseStep атомарно принимает интервал, false - код этого или более раннего интервала уже использован
+	UseStep(ctx context.Context, userID int32, step int64) (bool, error)
+	// ConsumeRecoveryCode атомарно удаляет код восстановления, false - такого кода нет
+	ConsumeRecoveryCode(ctx context.Context, userID int32, hash string) (bool, error)
+	// MarkChallengeUsed false - mfa токен уже был обменян на сессию
+	MarkChallengeUsed(ctx context.Context, tokenID string, expiredAt time.Time) (bool, error)
+}
+
+type MFAUsersRepository interface {
+	FetchUsersByIDs(ctx context.Context, ids []int32) (map[int32]models.User, error)
+}
+
+type SessionIssuer interface {
+	IssueSession(ctx context.Context, userID int32, client models.ClientInfo) (models.JWTToken, error)
+}
+
+type MFAConfig struct {
+	// Issuer название сервиса в приложении-аутентификаторе
+	Issuer string
+	// ChallengeTTL сколько живет промежуточный токен между вводом пароля и кода
+	ChallengeTTL time.Duration
+}
+
+type MFAService struct {
+	repo      TOTPRepository
+	usersRepo MFAUsersRepository
+	parser    TokenParser
+	issuer    SessionIssuer
+	throttle  *loginThrottle
+	config    MFAConfig
+}
+
+// Enroll создает новый секрет. Второй фактор включится только после Confirm,
+// до этого повторный Enroll просто заменяет секрет.
+func (s *MFAService) Enroll(ctx context.Context, userID int32) (models.TOTPEnrollment, error) {
+	factor, err := s.repo.Fetch(ctx, userID)
+	if err != nil && !errors.Is(err, models.ErrNotFound) {
+		return models.TOTPEnrollment{}, errors.Wrap(err, "totp repo err")
+	}
+
+	if factor.Confirmed {
+		return models.TOTPEnrollment{}, models.ErrMFAAlreadyEnabled
+	}
+
+	users, err := s.usersRepo.FetchUsersByIDs(ctx, []int32{userID})
+	if err != nil {
+		return models.TOTPEnrollment{}, errors.Wrap(err, "failed to fetch user")
+	}
+
+	user, ok := users[userID]
+	if !ok {
+		return models.TOTPEnrollment{}, models.ErrNotFound
+	}
+
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      s.config.Issuer,
+		AccountName: user.Email,
+		Period:      totpPeriod,
+		Digits:      totpDigits,
+		Algorithm:   otp.AlgorithmSHA1,
+	})
+	if err != nil {
+		return models.TOTPEnrollment{}, errors.Wrap(err, "failed to generate totp secret")
+	}
+
+	img, err := key.Image(qrCodeSize, qrCodeSize)
+	if err != nil {
+		return models.TOTPEnrollment{}, errors.Wrap(err, "failed to render qr code")
+	}
+
+	var qrCode bytes.Buffer
+
+	err = png.Encode(&qrCode, img)
+	if err != nil {
+		return models.TOTPEnrollment{}, errors.Wrap(err, "failed to encode qr code")
+	}
+
+	err = s.repo.Save(ctx, models.TOTPFactor{
+		UserID: userID,
+		Secret: key.Secret(),
+	})
+	if err != nil {
+		return models.TOTPEnrollment{}, errors.Wrap(err, "totp repo err")
+	}
+
+	return models.TOTPEnrollment{
+		Secret: key.Secret(),
+		URI:    key.URL(),
+		QRCode: qrCode.Bytes(),
+	}, nil
+}
+
+// Confirm включает второй фактор первым кодом из приложения и возвращает коды восстановления.
+// Коды показываются один раз, хранятся только их хэши.
+func (s *MFAService) Confirm(ctx context.Context, userID int32, code string) ([]string, error) {
+	factor, err := s.repo.Fetch(ctx, userID)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, models.ErrMFANotEnrolled
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "totp repo err")
+	}
+
+	if factor.Confirmed {
+		return nil, models.ErrMFAAlreadyEnabled
+	}
+
+	step, ok := matchTOTP(factor.Secret, normalizeCode(code), time.Now())
+	if !ok {
+		return nil, invalidMFACode()
+	}
+
+	codes := make([]string, 0, recoveryCodesCount)
+	hashes := make([]string, 0, recoveryCodesCount)
+
+	for range recoveryCodesCount {
+		recoveryCode, err := generateRecoveryCode()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to generate recovery code")
+		}
+
+		codes = append(codes, recoveryCode)
+		hashes = append(hashes, helpers.HashToken(normalizeCode(recoveryCode)))
+	}
+
+	factor.Confirmed = true
+	factor.RecoveryCodeHashes = hashes
+	factor.LastUsedStep = step
+
+	err = s.repo.Save(ctx, factor)
+	if err != nil {
+		return nil, errors.Wrap(err, "totp repo err")
+	}
+
+	return codes, nil
+}
+
+// Disable выключает второй фактор, требуя действующий код или код восстановления
+func (s *MFAService) Disable(ctx context.Context, userID int32, code string) error {
+	factor, err := s.repo.Fetch(ctx, userID)
+	if errors.Is(err, models.ErrNotFound) {
+		return models.ErrMFANotEnrolled
+	}
+	if err != nil {
+		return errors.Wrap(err, "totp repo err")
+	}
+
+	if !factor.Confirmed {
+		return s.repo.Delete(ctx, userID)
+	}
+
+	ok, err := s.checkCode(ctx, factor, code)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return invalidMFACode()
+	}
+
+	return s.repo.Delete(ctx, userID)
+}
+
+// Verify обменивает mfa токен из LoginService.Login и код на пару токенов.
+// Неверные коды учитываются так же, как неудачные попытки входа.
+func (s *MFAService) Verify(ctx context.Context, mfaToken, code string, client models.ClientInfo) (models.JWTToken, error) {
+	claims, err := s.parser.Parse(mfaToken, models.MFAAudience)
+	if err != nil {
+		return models.JWTToken{}, models.ErrInvalidMFAToken
+	}
+
+	sub, _ := claims.GetSubject()
+	tokenID, _ := claims["jti"].(string)
+	expiredAt, _ := claims.GetExpirationTime()
+
+	userID, err := strconv.ParseInt(sub, 10, 32)
+	if err != nil || len(tokenID) == 0 || expiredAt == nil {
+		return models.JWTToken{}, models.ErrInvalidMFAToken
+	}
+
+	keys := s.throttle.mfaKeys(int32(userID))
+
+	err = s.throttle.reserve(ctx, keys)
+	if err != nil {
+		return models.JWTToken{}, err
+	}
+
+	factor, err := s.repo.Fetch(ctx, int32(userID))
+	if errors.Is(err, models.ErrNotFound) || !factor.Confirmed {
+		// второй фактор выключили, пока пользователь вводил код
+		return models.JWTToken{}, models.ErrInvalidMFAToken
+	}
+	if err != nil {
+		return models.JWTToken{}, errors.Wrap(err, "totp repo err")
+	}
+
+	ok, err := s.checkCode(ctx, factor, code)
+	if err != nil {
+		return models.JWTToken{}, err
+	}
+
+	if !ok {
+		return models.JWTToken{}, invalidMFACode()
+	}
+
+	err = s.throttle.succeed(ctx, keys)
+	if err != nil {
+		return models.JWTToken{}, err
+	}
+
+	fresh, err := s.repo.MarkChallengeUsed(ctx, tokenID, expiredAt.Time)
+	if err != nil {
+		return models.JWTToken{}, errors.Wrap(err, "totp repo err")
+	}
+
+	if !fresh {
+		return models.JWTToken{}, models.ErrMFAChallengeReused
+	}
+
+	return s.issuer.IssueSession(ctx, int32(userID), client)
+}
+
+// checkCode принимает код из приложения или код восстановления. Оба одноразовые:
+// код из приложения нельзя повторить в том же интервале, код восстановления удаляется.
+func (s *MFAService) checkCode(ctx context.Context, factor models.TOTPFactor, code string) (bool, error) {
+	code = normalizeCode(code)
+
+	if len(code) == int(totpDigits) {
+		step, ok := matchTOTP(factor.Secret, code, time.Now())
+		if !ok {
+			return false, nil
+		}
+
+		ok, err := s.repo.UseStep(ctx, factor.UserID, step)
+		if err != nil {
+			return false, errors.Wrap(err, "totp repo err")
+		}
+
+		return ok, nil
+	}
+
+	ok, err := s.repo.ConsumeRecoveryCode(ctx, factor.UserID, helpers.HashToken(code))
+	if err != nil {
+		return false, errors.Wrap(err, "totp repo err")
+	}
+
+	return ok, nil
+}
+
+// matchTOTP возвращает номер интервала, которому соответствует код. Библиотечный
+// totp.Validate его не отдает, а без него нельзя запретить повторное использование кода.
+func matchTOTP(secret, code string, now time.Time) (int64, bool) {
+	opts := totp.ValidateOpts{
+		Period:    totpPeriod,
+		Digits:    totpDigits,
+		Algorithm: otp.AlgorithmSHA1,
+	}
+
+	for skew := -totpSkew; skew <= totpSkew; skew++ {
+		at := now.Add(time.Duration(skew*totpPeriod) * time.Second)
+
+		expected, err := totp.GenerateCodeCustom(secret, at, opts)
+		if err != nil {
+			return 0, false
+		}
+
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return at.Unix() / totpPeriod, true
+		}
+	}
+
+	return 0, false
+}
+
+func normalizeCode(code string) string {
+	code = strings.ReplaceAll(code, recoveryCodeDivider, "")
+	code = strings.ReplaceAll(code, " ", "")
+
+	return strings.ToUpper(code)
+}
+
+// generateRecoveryCode код вида ABCDE-FGHIJ, 50 бит энтропии
+func generateRecoveryCode() (string, error) {
+	// base32 кодирует 5 бит символом, лишние символы отрезаем
+	b := make([]byte, (recoveryCodeLength*5+7)/8)
+
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	code := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b)[:recoveryCodeLength]
+
+	return code[:recoveryCodeLength/2] + recoveryCodeDivider + code[recoveryCodeLength/2:], nil
+}
+
+func invalidMFACode() error {
+	return models.ValidationError{Fields: []models.FieldError{{
+		Field:   models.FieldCode,
+		Code:    models.ErrCodeInvalid,
+		Message: models.ErrInvalidMFACode.Error(),
+	}}}
+}
+
+func NewMFAService(
+	repo TOTPRepository,
+	usersRepo MFAUsersRepository,
+	parser TokenParser,
+	issuer SessionIssuer,
+	attemptsRepo LoginAttemptsRepository,
+	c Config,
+) *MFAService {
+	config := c.MFA
+	if len(config.Issuer) == 0 {
+		config.Issuer = defaultMFAIssuer
+	}
+
+	return &MFAService{
+		repo:      repo,
+		usersRepo: usersRepo,
+		parser:    parser,
+		issuer:    issuer,
+		throttle:  newLoginThrottle(attemptsRepo, c.Throttle),
+		config:    config,
+	}
+}
@@ -0,0 +1,301 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"net/url"
+	"strings"
+	"time"
+	"twitter-bff/domain/models"
+	"twitter-bff/helpers"
+)
+
+const defaultOIDCAuthRequestTTL = 10 * time.Minute
+
+type OIDCClient interface {
+	// AuthCodeURL адрес страницы входа провайдера с PKCE challenge для codeVerifier
+	AuthCodeURL(ctx context.Context, provider, state, nonce, codeVerifier string) (string, error)
+	// Exchange обменивает код на токены и возвращает данные проверенного ID токена
+	Exchange(ctx context.Context, provider, code, codeVerifier string) (models.OIDCIdentity, error)
+}
+
+type OIDCAuthRequestRepository interface {
+	Save(ctx context.Context, request models.OIDCAuthRequest) error
+	// Consume атомарно забирает запрос, повторный вызов вернет models.ErrNotFound
+	Consume(ctx context.Context, stateHash string) (models.OIDCAuthRequest, error)
+}
+
+type OIDCIdentityRepository interface {
+	UserIDBySubject(ctx context.Context, provider, subject string) (int32, error)
+	Link(ctx context.Context, provider, subject string, userID int32) error
+}
+
+type OIDCUsersRepository interface {
+	FetchUserByEmail(ctx context.Context, email string) (models.User, error)
+	Create(ctx context.Context, name string, passwordHash string, username string, email string) (models.User, error)
+}
+
+type SessionStarter interface {
+	StartSession(ctx context.Context, userID int32, client models.ClientInfo) (models.LoginResult, error)
+}
+
+type OIDCConfig struct {
+	// AuthRequestTTL сколько ждем возврата пользователя от провайдера
+	AuthRequestTTL time.Duration
+	// FrontendURL страница фронтенда, куда пользователь возвращается после входа
+	FrontendURL string
+}
+
+type OIDCService struct {
+	client       OIDCClient
+	requests     OIDCAuthRequestRepository
+	identities   OIDCIdentityRepository
+	usersRepo    OIDCUsersRepository
+	verification EmailVerificationRepository
+	sessions     SessionStarter
+	config       OIDCConfig
+}
+
+// Authorize начинает вход через провайдера. Возвращает адрес для редиректа и state,
+// который нужно сохранить в браузере, чтобы на callback убедиться, что вход начинал тот же браузер.
+func (s *OIDCService) Authorize(ctx context.Context, provider string) (string, string, error) {
+	state, err := helpers.GenerateRandomToken()
+	if err != nil {
+		return "", "", errors.Wrap(err, "failed to generate state")
+	}
+
+	nonce, err := helpers.GenerateRandomToken()
+	if err != nil {
+		return "", "", errors.Wrap(err, "failed to generate nonce")
+	}
+
+	// 32 случайных байта в base64url дают 43 символа, минимум для PKCE verifier по RFC 7636
+	codeVerifier, err := helpers.GenerateRandomToken()
+	if err != nil {
+		return "", "", errors.Wrap(err, "failed to generate code verifier")
+	}
+
+	authURL, err := s.client.AuthCodeURL(ctx, provider, state, nonce, codeVerifier)
+	if err != nil {
+		return "", "", err
+	}
+
+	err = s.requests.Save(ctx, models.OIDCAuthRequest{
+		StateHash:    helpers.HashToken(state),
+		Provider:     provider,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		ExpiredAt:    time.Now().Add(s.config.AuthRequestTTL),
+	})
+	if err != nil {
+		return "", "", errors.Wrap(err, "oidc auth request repo err")
+	}
+
+	return authURL, state, nil
+}
+
+// Callback завершает вход: проверяет state и nonce, находит или создает пользователя и начинает сессию
+func (s *OIDCService) Callback(
+	ctx context.Context,
+	provider, state, browserState, code string,
+	client models.ClientInfo,
+) (models.LoginResult, error) {
+	if len(state) == 0 || state != browserState {
+		return models.LoginResult{}, models.ErrInvalidOIDCState
+	}
+
+	request, err := s.requests.Consume(ctx, helpers.HashToken(state))
+	if errors.Is(err, models.ErrNotFound) {
+		return models.LoginResult{}, models.ErrInvalidOIDCState
+	}
+	if err != nil {
+		return models.LoginResult{}, errors.Wrap(err, "oidc auth request repo err")
+	}
+
+	if request.Provider != provider {
+		return models.LoginResult{}, models.ErrInvalidOIDCState
+	}
+
+	identity, err := s.client.Exchange(ctx, provider, code, request.CodeVerifier)
+	if err != nil {
+		return models.LoginResult{}, err
+	}
+
+	if identity.Nonce != request.Nonce {
+		return models.LoginResult{}, errors.Wrap(models.ErrInvalidIDToken, "nonce mismatch")
+	}
+
+	userID, err := s.resolveUser(ctx, identity)
+	if err != nil {
+		return models.LoginResult{}, err
+	}
+
+	return s.sessions.StartSession(ctx, userID, client)
+}
+
+// ResultURL адрес возврата на фронтенд. Ошибка передается параметром error, mfa токен -
+// во фрагменте, чтобы он не попадал в логи и заголовок Referer.
+func (s *OIDCService) ResultURL(result models.LoginResult, err error) string {
+	if err != nil {
+		return s.ErrorURL(oidcErrorCode(err))
+	}
+
+	if result.MFARequired {
+		fragment := url.Values{
+			"mfaToken":          {result.MFAToken},
+			"mfaTokenExpiresAt": {result.MFAExpiredAt.UTC().Format(time.RFC3339)},
+		}
+
+		return s.config.FrontendURL + "#" + fragment.Encode()
+	}
+
+	return s.config.FrontendURL
+}
+
+func (s *OIDCService) ErrorURL(code string) string {
+	separator := "?"
+	if strings.Contains(s.config.FrontendURL, "?") {
+		separator = "&"
+	}
+
+	return s.config.FrontendURL + separator + "error=" + url.QueryEscape(code)
+}
+
+func oidcErrorCode(err error) string {
+	switch {
+	case errors.Is(err, models.ErrUnknownOIDCProvider):
+		return "unknown_provider"
+	case errors.Is(err, models.ErrInvalidOIDCState):
+		return "invalid_state"
+	case errors.Is(err, models.ErrInvalidIDToken):
+		return "invalid_id_token"
+	case errors.Is(err, models.ErrOIDCEmailNotVerified):
+		return "email_not_verified"
+	case errors.Is(err, models.ErrOIDCAccountNotVerified):
+		return "account_not_verified"
+	default:
+		return "server_error"
+	}
+}
+
+// resolveUser ищет пользователя по привязанной учетке провайдера, затем по email, а если такого нет -
+// создает нового. По email находим только аккаунт, в котором адрес подтвержден письмом от нас:
+// аккаунт с неподтвержденным адресом мог заранее завести кто-то другой и знать от него пароль.
+func (s *OIDCService) resolveUser(ctx context.Context, identity models.OIDCIdentity) (int32, error) {
+	userID, err := s.identities.UserIDBySubject(ctx, identity.Provider, identity.Subject)
+	if err == nil {
+		return userID, nil
+	}
+	if !errors.Is(err, models.ErrNotFound) {
+		return 0, errors.Wrap(err, "oidc identity repo err")
+	}
+
+	if len(identity.Email) == 0 {
+		return 0, errors.Wrap(models.ErrInvalidIDToken, "email claim is required")
+	}
+
+	if !identity.EmailVerified {
+		return 0, models.ErrOIDCEmailNotVerified
+	}
+
+	user, err := s.usersRepo.FetchUserByEmail(ctx, identity.Email)
+	switch {
+	case err == nil:
+		verified, err := s.verification.IsVerified(ctx, user.ID, user.Email)
+		if err != nil {
+			return 0, errors.Wrap(err, "email verification repo err")
+		}
+
+		if !verified {
+			return 0, models.ErrOIDCAccountNotVerified
+		}
+	case errors.Is(err, models.ErrNotFound):
+		user, err = s.createUser(ctx, identity)
+		if err != nil {
+			return 0, err
+		}
+
+		// адрес подтвержден провайдером, второе письмо новому пользователю не нужно
+		err = s.verification.MarkVerified(ctx, user.ID, user.Email)
+		if err != nil {
+			return 0, errors.Wrap(err, "email verification repo err")
+		}
+	default:
+		return 0, errors.Wrap(err, "failed to fetch user")
+	}
+
+	err = s.identities.Link(ctx, identity.Provider, identity.Subject, user.ID)
+	if err != nil {
+		return 0, errors.Wrap(err, "oidc identity repo err")
+	}
+
+	return user.ID, nil
+}
+
+// createUser заводит пользователя без пароля: хэш от случайной строки, которую никто не знает.
+// Такой пользователь входит только через провайдера.
+func (s *OIDCService) createUser(ctx context.Context, identity models.OIDCIdentity) (models.User, error) {
+	secret, err := helpers.GenerateRandomToken()
+	if err != nil {
+		return models.User{}, errors.Wrap(err, "failed to generate password")
+	}
+
+	passwordHash, err := helpers.GenerateHash(secret)
+	if err != nil {
+		return models.User{}, errors.Wrap(err, "failed to hash password")
+	}
+
+	username := identity.PreferredUsername
+	if len(username) == 0 {
+		username, _, _ = strings.Cut(identity.Email, "@")
+	}
+
+	name := identity.Name
+	if len(name) == 0 {
+		name = username
+	}
+
+	user, err := s.usersRepo.Create(ctx, name, passwordHash, username, identity.Email)
+	if err == nil {
+		return user, nil
+	}
+
+	// имя могло быть занято, пробуем еще раз со случайным суффиксом
+	suffix, suffixErr := helpers.GenerateRandomToken()
+	if suffixErr != nil {
+		return models.User{}, errors.Wrap(err, "failed to create user")
+	}
+
+	user, err = s.usersRepo.Create(ctx, name, passwordHash, fmt.Sprintf("%s_%s", username, strings.ToLower(suffix[:6])), identity.Email)
+	if err != nil {
+		return models.User{}, errors.Wrap(err, "failed to create user")
+	}
+
+	return user, nil
+}
+
+func NewOIDCService(
+	client OIDCClient,
+	requests OIDCAuthRequestRepository,
+	identities OIDCIdentityRepository,
+	usersRepo OIDCUsersRepository,
+	verification EmailVerificationRepository,
+	sessions SessionStarter,
+	c Config,
+) *OIDCService {
+	config := c.OIDC
+	if config.AuthRequestTTL == 0 {
+		config.AuthRequestTTL = defaultOIDCAuthRequestTTL
+	}
+
+	return &OIDCService{
+		client:       client,
+		requests:     requests,
+		identities:   identities,
+		usersRepo:    usersRepo,
+		verification: verification,
+		sessions:     sessions,
+		config:       config,
+	}
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"strings"
+	"twitter-bff/domain/models"
+	"unicode"
+)
+
+const (
+	defaultPasswordMinLength = 8
+	// bcrypt молча отбрасывает все после 72 байт, поэтому более длинный пароль запрещаем явно
+	bcryptMaxPasswordBytes = 72
+	// слишком короткие имя или email встречаются в паролях случайно, их не проверяем
+	minBannedSubstringLength = 3
+)
+
+type BreachedPasswordChecker interface {
+	IsBreached(ctx context.Context, password string) (bool, error)
+}
+
+type PasswordPolicyConfig struct {
+	MinLength int
+	// MinCharClasses сколько классов символов (строчные, заглавные, цифры, прочие) должно быть в пароле.
+	// 0 отключает проверку.
+	MinCharClasses int
+}
+
+type PasswordPolicy struct {
+	breached BreachedPasswordChecker
+	config   PasswordPolicyConfig
+}
+
+// Validate возвращает models.ValidationError со всеми нарушенными правилами сразу,
+// чтобы пользователь не исправлял пароль по одной ошибке за попытку.
+func (p *PasswordPolicy) Validate(ctx context.Context, password, username, email string) error {
+	var fields []models.FieldError
+
+	addError := func(code, message string) {
+		fields = append(fields, models.FieldError{
+			Field:   models.FieldPassword,
+			Code:    code,
+			Message: message,
+		})
+	}
+
+	if len([]rune(password)) < p.config.MinLength {
+		addError(models.ErrCodeTooShort, fmt.Sprintf("password must be at least %d characters", p.config.MinLength))
+	}
+
+	if len(password) > bcryptMaxPasswordBytes {
+		addError(models.ErrCodeTooLong, fmt.Sprintf("password must be at most %d bytes", bcryptMaxPasswordBytes))
+	}
+
+	if p.config.MinCharClasses > 0 && charClasses(password) < p.config.MinCharClasses {
+		addError(models.ErrCodeTooSimple, fmt.Sprintf(
+			"password must contain at least %d of: lowercase, uppercase, digits, symbols", p.config.MinCharClasses,
+		))
+	}
+
+	lowerPassword := strings.ToLower(password)
+
+	if containsFold(lowerPassword, username) {
+		addError(models.ErrCodeContainsUsername, "password must not contain username")
+	}
+
+	localPart, _, _ := strings.Cut(email, "@")
+	if containsFold(lowerPassword, localPart) {
+		addError(models.ErrCodeContainsEmail, "password must not contain email")
+	}
+
+	breached, err := p.breached.IsBreached(ctx, password)
+	if err != nil {
+		return errors.Wrap(err, "breached password check err")
+	}
+
+	if breached {
+		addError(models.ErrCodeBreached, "password has appeared in a data breach, choose another one")
+	}
+
+	if len(fields) != 0 {
+		return models.ValidationError{Fields: fields}
+	}
+
+	return nil
+}
+
+func containsFold(lowerPassword, value string) bool {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if len([]rune(value)) < minBannedSubstringLength {
+		return false
+	}
+
+	return strings.Contains(lowerPassword, value)
+}
+
+func charClasses(password string) int {
+	var lower, upper, digit, other int
+
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		default:
+			other = 1
+		}
+	}
+
+	return lower + upper + digit + other
+}
+
+func NewPasswordPolicy(breached BreachedPasswordChecker, c Config) *PasswordPolicy {
+	config := c.Password
+	if config.MinLength == 0 {
+		config.MinLength = defaultPasswordMinLength
+	}
+
+	return &PasswordPolicy{
+		breached: breached,
+		config:   config,
+	}
+}
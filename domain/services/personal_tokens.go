@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"sort"
+	"strings"
+	"time"
+	"twitter-bff/domain/models"
+	"twitter-bff/helpers"
+)
+
+const maxPersonalTokenNameLength = 100
+
+type PersonalTokenRepository interface {
+	Save(ctx context.Context, token models.PersonalToken) error
+	FetchByHash(ctx context.Context, hash string) (models.PersonalToken, error)
+	ListByUser(ctx context.Context, userID int32) ([]models.PersonalToken, error)
+	Delete(ctx context.Context, userID int32, id string) error
+	DeleteByUser(ctx context.Context, userID int32) error
+	Touch(ctx context.Context, id string, lastUsedAt time.Time) error
+}
+
+type PersonalTokensService struct {
+	repo PersonalTokenRepository
+}
+
+// Create выпускает персональный токен. Значение возвращается только здесь, хранится хэш.
+func (s *PersonalTokensService) Create(
+	ctx context.Context,
+	userID int32,
+	name string,
+	scopes []string,
+	expiredAt time.Time,
+) (models.PersonalToken, string, error) {
+	name = strings.TrimSpace(name)
+	scopes = lo.Uniq(scopes)
+
+	err := validatePersonalToken(name, scopes, expiredAt)
+	if err != nil {
+		return models.PersonalToken{}, "", err
+	}
+
+	random, err := helpers.GenerateRandomToken()
+	if err != nil {
+		return models.PersonalToken{}, "", errors.Wrap(err, "failed to generate personal token")
+	}
+
+	raw := models.PersonalTokenPrefix + random
+
+	token := models.PersonalToken{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		Name:      name,
+		Hash:      helpers.HashToken(raw),
+		Scopes:    scopes,
+		ExpiredAt: expiredAt,
+		CreatedAt: time.Now(),
+	}
+
+	err = s.repo.Save(ctx, token)
+	if err != nil {
+		return models.PersonalToken{}, "", errors.Wrap(err, "personal token repo err")
+	}
+
+	return token, raw, nil
+}
+
+// List возвращает токены пользователя, новые первыми. Истекшие тоже показываются,
+// чтобы было понятно, какой скрипт перестал работать.
+func (s *PersonalTokensService) List(ctx context.Context, userID int32) ([]models.PersonalToken, error) {
+	tokens, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "personal token repo err")
+	}
+
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].CreatedAt.After(tokens[j].CreatedAt)
+	})
+
+	return tokens, nil
+}
+
+func (s *PersonalTokensService) Revoke(ctx context.Context, userID int32, id string) error {
+	err := s.repo.Delete(ctx, userID, id)
+	if err != nil {
+		return errors.Wrap(err, "personal token repo err")
+	}
+
+	return nil
+}
+
+// Authenticate проверяет токен из заголовка Authorization и возвращает его владельца с правами токена
+func (s *PersonalTokensService) Authenticate(ctx context.Context, raw string) (models.JWTUser, error) {
+	token, err := s.repo.FetchByHash(ctx, helpers.HashToken(raw))
+	if errors.Is(err, models.ErrNotFound) {
+		return models.JWTUser{}, models.ErrInvalidPersonalToken
+	}
+	if err != nil {
+		return models.JWTUser{}, errors.Wrap(err, "personal token repo err")
+	}
+
+	now := time.Now()
+
+	if token.IsExpired(now) {
+		return models.JWTUser{}, models.ErrInvalidPersonalToken
+	}
+
+	err = s.repo.Touch(ctx, token.ID, now)
+	if err != nil {
+		return models.JWTUser{}, errors.Wrap(err, "personal token repo err")
+	}
+
+	return models.JWTUser{
+		UserID:        token.UserID,
+		IssuedAt:      token.CreatedAt,
+		ExpiredAt:     token.ExpiredAt,
+		PersonalToken: true,
+		Scopes:        token.Scopes,
+	}, nil
+}
+
+func validatePersonalToken(name string, scopes []string, expiredAt time.Time) error {
+	var fields []models.FieldError
+
+	if len(name) == 0 || len([]rune(name)) > maxPersonalTokenNameLength {
+		fields = append(fields, models.FieldError{
+			Field:   models.FieldName,
+			Code:    models.ErrCodeInvalid,
+			Message: "name must be between 1 and 100 characters",
+		})
+	}
+
+	_, unknown := lo.Difference(models.Scopes, scopes)
+	if len(scopes) == 0 || len(unknown) != 0 {
+		fields = append(fields, models.FieldError{
+			Field:   models.FieldScopes,
+			Code:    models.ErrCodeInvalid,
+			Message: "scopes must be a non-empty subset of " + strings.Join(models.Scopes, ", "),
+		})
+	}
+
+	if !expiredAt.IsZero() && !expiredAt.After(time.Now()) {
+		fields = append(fields, models.FieldError{
+			Field:   models.FieldExpiresAt,
+			Code:    models.ErrCodeInvalid,
+			Message: "expiresAt must be in the future",
+		})
+	}
+
+	if len(fields) != 0 {
+		return models.ValidationError{Fields: fields}
+	}
+
+	return nil
+}
+
+func NewPersonalTokensService(repo PersonalTokenRepository) *PersonalTokensService {
+	return &PersonalTokensService{
+		repo: repo,
+	}
+}
@@ -4,29 +4,91 @@ import (
 	"context"
 	"github.com/pkg/errors"
 	"github.com/samber/lo"
+	"sort"
+	"time"
 	"twitter-bff/domain/models"
 )
 
-const defaultPostLimit = 100
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+	// maxPostsWindow сколько постов BFF готов запросить у twitter-posts ради одной страницы.
+	// Глубже этой границы лента не листается.
+	maxPostsWindow = 1000
+)
 
 type PostsRepository interface {
 	Create(ctx context.Context, userID int32, body string) (models.Post, error)
-	PostsByUserID(ctx context.Context, userID int32) ([]models.Post, error)
+	PostsByUserID(ctx context.Context, userID, currentUserID, limit int32) ([]models.Post, error)
 	LatestPosts(ctx context.Context, userIDs []int32, currentUserId, limit int32) ([]models.Post, error)
 	PostByID(ctx context.Context, postID int32, userID int32) (models.Post, error)
+	// PostsByIDs посты по ID, удаленных в результате нет
+	PostsByIDs(ctx context.Context, postIDs []int32, userID int32) (map[int32]models.Post, error)
 	CommentsByPostID(ctx context.Context, postID int32) ([]models.Comment, error)
 }
 
+// ReplyRepository связи ответов с постами. twitter-posts об ответах не знает, связь хранит BFF.
+type ReplyRepository interface {
+	SaveReply(ctx context.Context, postID, parentID int32) error
+	// Parents родители постов, которые являются ответами
+	Parents(ctx context.Context, postIDs []int32) (map[int32]int32, error)
+	// Replies ID ответов на пост в порядке создания
+	Replies(ctx context.Context, postID int32) ([]int32, error)
+	ReplyCounts(ctx context.Context, postIDs []int32) (map[int32]int32, error)
+}
+
+// RepostRepository репосты и связи цитат с цитируемыми постами. Как и ответы, их хранит BFF.
+type RepostRepository interface {
+	SaveRepost(ctx context.Context, userID, postID int32, createdAt time.Time) (models.Repost, error)
+	DeleteRepost(ctx context.Context, userID, postID int32) error
+	// RepostsByUsers последний репост каждого поста пользователями userIDs, если он идет после курсора,
+	// от новых к старым, не больше limit
+	RepostsByUsers(ctx context.Context, userIDs []int32, cursor models.PostCursor, limit int32) ([]models.Repost, error)
+	RepostCounts(ctx context.Context, postIDs []int32) (map[int32]int32, error)
+	// RepostedByUsers какие из постов репостнул хотя бы один из userIDs
+	RepostedByUsers(ctx context.Context, userIDs, postIDs []int32) (map[int32]bool, error)
+	SaveQuote(ctx context.Context, postID, quotedPostID int32) error
+	// QuotedPosts цитируемые посты для постов-цитат
+	QuotedPosts(ctx context.Context, postIDs []int32) (map[int32]int32, error)
+}
+
+// HashtagRepository индекс хэштегов постов на стороне BFF
+type HashtagRepository interface {
+	// SaveHashtags заменяет теги поста, post - его позиция в ленте
+	SaveHashtags(ctx context.Context, post models.PostCursor, tags []string) error
+	// PostsByHashtag позиции постов с тегом после курсора, от новых к старым
+	PostsByHashtag(ctx context.Context, tag string, cursor models.PostCursor, limit int32) ([]models.PostCursor, error)
+	SearchHashtags(ctx context.Context, prefix string, limit int32) ([]models.Hashtag, error)
+}
+
 type PostsUsersByIDsRepository interface {
 	FetchUsersByIDs(ctx context.Context, ids []int32) (map[int32]models.User, error)
 }
 
+// PostingRestrictionRepository хранит аккаунты, которым администратор запретил публиковать посты
+type PostingRestrictionRepository interface {
+	IsPostingDisabled(ctx context.Context, userID int32) (bool, error)
+	SetPostingDisabled(ctx context.Context, userID int32, disabled bool) error
+}
+
 type PostsService struct {
-	repo      PostsRepository
-	usersRepo PostsUsersByIDsRepository
+	repo         PostsRepository
+	usersRepo    PostsUsersByIDsRepository
+	verification EmailVerificationChecker
+	restrictions PostingRestrictionRepository
+	replies      ReplyRepository
+	reposts      RepostRepository
+	hashtags     HashtagRepository
 }
 
-func (s *PostsService) Create(ctx context.Context, userID int32, body string) (models.Post, error) {
+// Create публикует пост. inReplyToPostID - пост, на который это ответ, quotedPostID - цитируемый пост,
+// 0 в каждом из них означает, что связи нет.
+func (s *PostsService) Create(
+	ctx context.Context,
+	userID int32,
+	body string,
+	inReplyToPostID, quotedPostID int32,
+) (models.Post, error) {
 	if userID == 0 {
 		return models.Post{}, errors.Wrap(models.ErrInvalidArgument, "invalid user id")
 	}
@@ -35,11 +97,59 @@ func (s *PostsService) Create(ctx context.Context, userID int32, body string) (m
 		return models.Post{}, errors.Wrap(models.ErrInvalidArgument, "invalid post body")
 	}
 
+	err := s.checkCanPost(ctx, userID)
+	if err != nil {
+		return models.Post{}, err
+	}
+
+	err = s.checkLinkedPost(ctx, inReplyToPostID, userID, models.FieldInReplyToPostID, "post to reply to does not exist")
+	if err != nil {
+		return models.Post{}, err
+	}
+
+	err = s.checkLinkedPost(ctx, quotedPostID, userID, models.FieldQuotedPostID, "post to quote does not exist")
+	if err != nil {
+		return models.Post{}, err
+	}
+
 	post, err := s.repo.Create(ctx, userID, body)
 	if err != nil {
 		return models.Post{}, errors.Wrap(err, "create repo err")
 	}
 
+	if inReplyToPostID != 0 {
+		err = s.replies.SaveReply(ctx, post.ID, inReplyToPostID)
+		if err != nil {
+			return models.Post{}, errors.Wrap(err, "reply repo err")
+		}
+	}
+
+	if quotedPostID != 0 {
+		err = s.reposts.SaveQuote(ctx, post.ID, quotedPostID)
+		if err != nil {
+			return models.Post{}, errors.Wrap(err, "repost repo err")
+		}
+	}
+
+	err = s.hashtags.SaveHashtags(ctx, models.CursorOf(post), models.ExtractHashtags(post.Body))
+	if err != nil {
+		return models.Post{}, errors.Wrap(err, "hashtag repo err")
+	}
+
+	posts := []models.Post{post}
+
+	err = s.fillIndexed(ctx, posts, userID)
+	if err != nil {
+		return models.Post{}, err
+	}
+
+	err = s.embedQuotes(ctx, posts, userID)
+	if err != nil {
+		return models.Post{}, err
+	}
+
+	post = posts[0]
+
 	usersByID, err := s.usersRepo.FetchUsersByIDs(ctx, []int32{userID})
 	if err != nil {
 		return models.Post{}, errors.Wrap(err, "get users err")
@@ -50,140 +160,506 @@ func (s *PostsService) Create(ctx context.Context, userID int32, body string) (m
 	return post, nil
 }
 
-func (s *PostsService) PostsByUserID(ctx context.Context, userID int32) ([]models.Post, error) {
-	if userID == 0 {
-		return nil, errors.Wrap(models.ErrInvalidArgument, "invalid user id")
+// checkLinkedPost проверяет пост, на который ссылается новый пост. 0 - ссылки нет.
+func (s *PostsService) checkLinkedPost(ctx context.Context, postID, userID int32, field, message string) error {
+	if postID == 0 {
+		return nil
+	}
+
+	_, err := s.repo.PostByID(ctx, postID, userID)
+	if errors.Is(err, models.ErrNotFound) {
+		return models.ValidationError{Fields: []models.FieldError{{
+			Field:   field,
+			Code:    models.ErrCodeInvalid,
+			Message: message,
+		}}}
 	}
+	if err != nil {
+		return errors.Wrap(err, "posts repo err")
+	}
+
+	return nil
+}
 
-	posts, err := s.repo.PostsByUserID(ctx, userID)
+// hydratePosts дополняет посты из twitter-posts данными индекса, цитатами и авторами.
+// Каждый шаг делает один запрос на все посты, а не по запросу на пост.
+func (s *PostsService) hydratePosts(ctx context.Context, posts []models.Post, currentUserID int32) error {
+	err := s.fillIndexed(ctx, posts, currentUserID)
 	if err != nil {
-		return nil, errors.Wrap(err, "get posts err")
+		return err
 	}
 
+	err = s.embedQuotes(ctx, posts, currentUserID)
+	if err != nil {
+		return err
+	}
+
+	return s.withAuthors(ctx, posts)
+}
+
+// fillIndexed дополняет посты тем, что о них хранит BFF: ответами, репостами и цитатами
+func (s *PostsService) fillIndexed(ctx context.Context, posts []models.Post, currentUserID int32) error {
 	if len(posts) == 0 {
-		return []models.Post{}, nil
+		return nil
+	}
+
+	postIDs := lo.Map(posts, func(post models.Post, _ int) int32 {
+		return post.ID
+	})
+
+	parents, err := s.replies.Parents(ctx, postIDs)
+	if err != nil {
+		return errors.Wrap(err, "reply repo err")
+	}
+
+	replyCounts, err := s.replies.ReplyCounts(ctx, postIDs)
+	if err != nil {
+		return errors.Wrap(err, "reply repo err")
 	}
 
-	userIDs := lo.Uniq(lo.Map(posts, func(post models.Post, _ int) int32 {
-		return post.UserID
+	repostCounts, err := s.reposts.RepostCounts(ctx, postIDs)
+	if err != nil {
+		return errors.Wrap(err, "repost repo err")
+	}
+
+	reposted, err := s.reposts.RepostedByUsers(ctx, []int32{currentUserID}, postIDs)
+	if err != nil {
+		return errors.Wrap(err, "repost repo err")
+	}
+
+	quoted, err := s.reposts.QuotedPosts(ctx, postIDs)
+	if err != nil {
+		return errors.Wrap(err, "repost repo err")
+	}
+
+	for i, post := range posts {
+		posts[i].InReplyToPostID = parents[post.ID]
+		posts[i].ReplyCount = replyCounts[post.ID]
+		posts[i].RepostCount = repostCounts[post.ID]
+		posts[i].IsCurrentUserReposted = reposted[post.ID]
+		posts[i].QuotedPostID = quoted[post.ID]
+	}
+
+	return nil
+}
+
+// embedQuotes загружает цитируемые посты с авторами. Цитаты внутри цитируемого поста
+// не раскрываются, у него заполнен только QuotedPostID.
+func (s *PostsService) embedQuotes(ctx context.Context, posts []models.Post, currentUserID int32) error {
+	quotedIDs := lo.Uniq(lo.FilterMap(posts, func(post models.Post, _ int) (int32, bool) {
+		return post.QuotedPostID, post.QuotedPostID != 0
 	}))
 
-	usersByID, err := s.usersRepo.FetchUsersByIDs(ctx, userIDs)
+	if len(quotedIDs) == 0 {
+		return nil
+	}
+
+	quotedByID, err := s.repo.PostsByIDs(ctx, quotedIDs, currentUserID)
 	if err != nil {
-		return nil, errors.Wrap(err, "get users err")
+		return errors.Wrap(err, "posts repo err")
+	}
+
+	quoted := lo.Values(quotedByID)
+
+	err = s.fillIndexed(ctx, quoted, currentUserID)
+	if err != nil {
+		return err
 	}
 
+	err = s.withAuthors(ctx, quoted)
+	if err != nil {
+		return err
+	}
+
+	quotedByID = lo.KeyBy(quoted, func(post models.Post) int32 {
+		return post.ID
+	})
+
 	for i, post := range posts {
-		user := usersByID[post.UserID]
-		u := models.User{
-			ID:       user.ID,
-			Name:     user.Name,
-			Username: user.Username,
-			Email:    user.Email,
+		if quotedPost, ok := quotedByID[post.QuotedPostID]; ok {
+			posts[i].QuotedPost = &quotedPost
 		}
+	}
+
+	return nil
+}
+
+// withAuthors заполняет авторов постов и их комментариев публичными полями пользователя
+func (s *PostsService) withAuthors(ctx context.Context, posts []models.Post) error {
+	if len(posts) == 0 {
+		return nil
+	}
+
+	userIDs := make([]int32, 0, len(posts))
+	for _, post := range posts {
+		userIDs = append(userIDs, post.UserID)
+
+		for _, comment := range post.Comments {
+			userIDs = append(userIDs, comment.UserID)
+		}
+	}
+
+	usersByID, err := s.usersRepo.FetchUsersByIDs(ctx, lo.Uniq(userIDs))
+	if err != nil {
+		return errors.Wrap(err, "get users err")
+	}
+
+	for i, post := range posts {
+		posts[i].User = publicUser(usersByID[post.UserID])
+
+		for j, comment := range post.Comments {
+			posts[i].Comments[j].User = publicUser(usersByID[comment.UserID])
+		}
+	}
+
+	return nil
+}
+
+func publicUser(user models.User) models.User {
+	return models.User{
+		ID:       user.ID,
+		Name:     user.Name,
+		Username: user.Username,
+		Email:    user.Email,
+	}
+}
+
+func (s *PostsService) checkCanPost(ctx context.Context, userID int32) error {
+	err := s.verification.CheckVerified(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	disabled, err := s.restrictions.IsPostingDisabled(ctx, userID)
+	if err != nil {
+		return errors.Wrap(err, "posting restriction repo err")
+	}
+
+	if disabled {
+		return models.ErrPostingDisabled
+	}
+
+	return nil
+}
+
+// PostsByUserID посты пользователя userID. currentUserID - кто смотрит, 0 - анонимный запрос.
+func (s *PostsService) PostsByUserID(
+	ctx context.Context,
+	userID, currentUserID int32,
+	page models.PageRequest,
+) (models.PostsPage, error) {
+	if userID == 0 {
+		return models.PostsPage{}, errors.Wrap(models.ErrInvalidArgument, "invalid user id")
+	}
+
+	result, err := paginatePosts(page, func(limit int32) ([]models.Post, error) {
+		return s.repo.PostsByUserID(ctx, userID, currentUserID, limit)
+	})
+	if err != nil {
+		return models.PostsPage{}, errors.Wrap(err, "get posts err")
+	}
 
-		posts[i].User = u
+	err = s.hydratePosts(ctx, result.Posts, currentUserID)
+	if err != nil {
+		return models.PostsPage{}, err
 	}
 
-	return posts, nil
+	return result, nil
 }
 
-func (s *PostsService) FeedPosts(ctx context.Context, userID int32) ([]models.Post, error) {
+// FeedPosts лента пользователя: посты его подписок и его собственные вместе с их репостами.
+// Пост, который репостнули, показывается один раз - последним репостом, а не на месте публикации.
+func (s *PostsService) FeedPosts(ctx context.Context, userID int32, page models.PageRequest) (models.FeedPage, error) {
 	if userID == 0 {
-		return []models.Post{}, nil
+		return models.FeedPage{Items: []models.FeedItem{}}, nil
+	}
+
+	cursor, err := models.DecodePostCursor(page.Cursor)
+	if err != nil {
+		return models.FeedPage{}, err
 	}
 
 	users, err := s.usersRepo.FetchUsersByIDs(ctx, []int32{userID})
 	if err != nil {
-		return nil, errors.Wrap(err, "get current user err")
+		return models.FeedPage{}, errors.Wrap(err, "get current user err")
 	}
 
 	currentUser, ok := users[userID]
 	if !ok {
-		return nil, errors.Wrap(models.ErrNotFound, "current user not found")
+		return models.FeedPage{}, errors.Wrap(models.ErrNotFound, "current user not found")
 	}
 
 	userIDs := make([]int32, len(currentUser.FollowingUserIds), len(currentUser.FollowingUserIds)+1)
 	copy(userIDs, currentUser.FollowingUserIds)
 	userIDs = append(userIDs, userID)
 
-	posts, err := s.repo.LatestPosts(ctx, userIDs, userID, defaultPostLimit)
+	result, err := paginatePosts(page, func(limit int32) ([]models.Post, error) {
+		return s.repo.LatestPosts(ctx, userIDs, userID, limit)
+	})
 	if err != nil {
-		return nil, errors.Wrap(err, "feed posts err")
+		return models.FeedPage{}, errors.Wrap(err, "feed posts err")
 	}
 
-	users, err = s.usersRepo.FetchUsersByIDs(ctx, userIDs)
+	limit := pageLimit(page)
+
+	// страницу займут не больше limit репостов после курсора, более старые репосты не нужны
+	reposts, err := s.reposts.RepostsByUsers(ctx, userIDs, cursor, limit)
 	if err != nil {
-		return nil, errors.Wrap(err, "get current user err")
+		return models.FeedPage{}, errors.Wrap(err, "repost repo err")
 	}
 
-	for i, post := range posts {
-		user := users[post.UserID]
-		u := models.User{
-			ID:       user.ID,
-			Name:     user.Name,
-			Username: user.Username,
-			Email:    user.Email,
+	// каждый пост попадает в ленту только последним репостом, в том числе на прошлых страницах
+	reposted, err := s.reposts.RepostedByUsers(ctx, userIDs, lo.Map(result.Posts, func(post models.Post, _ int) int32 {
+		return post.ID
+	}))
+	if err != nil {
+		return models.FeedPage{}, errors.Wrap(err, "repost repo err")
+	}
+
+	items := make([]models.FeedItem, 0, len(result.Posts)+len(reposts))
+	for _, post := range result.Posts {
+		if !reposted[post.ID] {
+			items = append(items, models.FeedItem{Post: post})
 		}
+	}
 
-		posts[i].User = u
+	// репосты старше последнего поста страницы попадут в следующие страницы вместе с постами
+	var boundary models.PostCursor
+	if len(result.NextCursor) > 0 {
+		boundary = models.CursorOf(result.Posts[len(result.Posts)-1])
 	}
 
-	return posts, nil
+	for _, repost := range reposts {
+		position := repost.Cursor()
+		if !boundary.IsZero() && boundary.Precedes(position) {
+			continue
+		}
+
+		items = append(items, models.FeedItem{Post: models.Post{ID: repost.PostID}, Repost: &repost})
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Cursor().Precedes(items[j].Cursor())
+	})
+
+	feed := models.FeedPage{NextCursor: result.NextCursor, Truncated: result.Truncated}
+
+	if int32(len(items)) > limit {
+		items = items[:limit]
+		feed.NextCursor = items[limit-1].Cursor().Encode()
+	} else if len(feed.NextCursor) > 0 && len(items) > 0 {
+		feed.NextCursor = items[len(items)-1].Cursor().Encode()
+	}
+
+	feed.Items, err = s.hydrateFeed(ctx, items, userID)
+	if err != nil {
+		return models.FeedPage{}, err
+	}
+
+	return feed, nil
 }
 
-func (s *PostsService) PostByID(ctx context.Context, postID int32, userID int32) (models.Post, error) {
+// hydrateFeed загружает репостнутые посты и заполняет посты ленты, их авторов и авторов репостов.
+// Репосты удаленных постов из ленты выпадают.
+func (s *PostsService) hydrateFeed(ctx context.Context, items []models.FeedItem, userID int32) ([]models.FeedItem, error) {
+	repostedIDs := lo.FilterMap(items, func(item models.FeedItem, _ int) (int32, bool) {
+		return item.Post.ID, item.Repost != nil
+	})
+
+	repostedPosts, err := s.repo.PostsByIDs(ctx, repostedIDs, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "posts repo err")
+	}
+
+	hydrated := make([]models.FeedItem, 0, len(items))
+	for _, item := range items {
+		if item.Repost != nil {
+			post, ok := repostedPosts[item.Repost.PostID]
+			if !ok {
+				continue
+			}
+
+			item.Post = post
+		}
+
+		hydrated = append(hydrated, item)
+	}
+
+	posts := lo.Map(hydrated, func(item models.FeedItem, _ int) models.Post {
+		return item.Post
+	})
+
+	err = s.fillIndexed(ctx, posts, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.embedQuotes(ctx, posts, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.withAuthors(ctx, posts)
+	if err != nil {
+		return nil, err
+	}
+
+	reposterIDs := lo.Uniq(lo.FilterMap(hydrated, func(item models.FeedItem, _ int) (int32, bool) {
+		if item.Repost == nil {
+			return 0, false
+		}
+
+		return item.Repost.UserID, true
+	}))
+
+	var reposters map[int32]models.User
+	if len(reposterIDs) > 0 {
+		reposters, err = s.usersRepo.FetchUsersByIDs(ctx, reposterIDs)
+		if err != nil {
+			return nil, errors.Wrap(err, "get users err")
+		}
+	}
+
+	for i, item := range hydrated {
+		hydrated[i].Post = posts[i]
+
+		if item.Repost != nil {
+			hydrated[i].RepostedBy = publicUser(reposters[item.Repost.UserID])
+		}
+	}
+
+	return hydrated, nil
+}
+
+// Repost репостит пост от имени userID. Повторный репост ничего не меняет.
+func (s *PostsService) Repost(ctx context.Context, userID, postID int32) (models.Post, error) {
 	if postID == 0 {
 		return models.Post{}, errors.Wrap(models.ErrInvalidArgument, "invalid post id")
 	}
 
-	post, err := s.repo.PostByID(ctx, postID, userID)
+	err := s.checkCanPost(ctx, userID)
+	if err != nil {
+		return models.Post{}, err
+	}
+
+	_, err = s.repo.PostByID(ctx, postID, userID)
 	if err != nil {
 		return models.Post{}, errors.Wrap(err, "posts repo err")
 	}
 
-	userIDs := make([]int32, 0, len(post.Comments)+1)
-	seen := make(map[int32]struct{}, len(post.Comments)+1)
+	_, err = s.reposts.SaveRepost(ctx, userID, postID, time.Now())
+	if err != nil {
+		return models.Post{}, errors.Wrap(err, "repost repo err")
+	}
 
-	userIDs = append(userIDs, post.UserID)
-	seen[post.UserID] = struct{}{}
+	return s.PostByID(ctx, postID, userID)
+}
 
-	for _, comment := range post.Comments {
-		if _, ok := seen[comment.ID]; ok {
-			continue
-		}
-		userIDs = append(userIDs, comment.UserID)
-		seen[comment.ID] = struct{}{}
+// Unrepost отменяет репост. Отмена несуществующего репоста не ошибка.
+func (s *PostsService) Unrepost(ctx context.Context, userID, postID int32) (models.Post, error) {
+	if userID == 0 {
+		return models.Post{}, errors.Wrap(models.ErrInvalidArgument, "invalid user id")
 	}
 
-	usersByID, err := s.usersRepo.FetchUsersByIDs(ctx, userIDs)
+	if postID == 0 {
+		return models.Post{}, errors.Wrap(models.ErrInvalidArgument, "invalid post id")
+	}
+
+	err := s.reposts.DeleteRepost(ctx, userID, postID)
 	if err != nil {
-		return models.Post{}, errors.Wrap(err, "get users err")
+		return models.Post{}, errors.Wrap(err, "repost repo err")
 	}
 
-	user := usersByID[post.UserID]
-	u := models.User{
-		ID:       user.ID,
-		Name:     user.Name,
-		Username: user.Username,
-		Email:    user.Email,
+	return s.PostByID(ctx, postID, userID)
+}
+
+// paginatePosts вырезает страницу после курсора. twitter-posts умеет только ограничить
+// количество постов, отсортированных по updated_at, поэтому BFF запрашивает окно, удваивая его,
+// пока после курсора не наберется страница, и сам упорядочивает посты по (created_at, id).
+//
+// Пост за пределами окна обновлен не позже самого старого обновления в окне, значит и создан
+// не позже. Поэтому посты окна, созданные не раньше этой границы, стоят в ленте на своих местах,
+// а более старые могут перемежаться с непришедшими и в страницу попадают только при следующем окне.
+//
+// Окно не растет дальше maxPostsWindow. Если и оно заполнено целиком, а страница после курсора
+// на нем заканчивается, у twitter-posts могут остаться посты, до которых BFF не дойдет: страница
+// возвращается без NextCursor и с Truncated.
+func paginatePosts(page models.PageRequest, fetch func(limit int32) ([]models.Post, error)) (models.PostsPage, error) {
+	cursor, err := models.DecodePostCursor(page.Cursor)
+	if err != nil {
+		return models.PostsPage{}, err
 	}
 
-	post.User = u
+	limit := pageLimit(page)
+
+	// на один пост больше страницы, чтобы узнать, есть ли следующая
+	window := limit + 1
+
+	for {
+		posts, err := fetch(window)
+		if err != nil {
+			return models.PostsPage{}, err
+		}
+
+		capped := window >= maxPostsWindow && int32(len(posts)) >= window
+		exhausted := int32(len(posts)) < window || window >= maxPostsWindow
 
-	for i, comment := range post.Comments {
-		user := usersByID[comment.UserID]
-		u := models.User{
-			ID:       user.ID,
-			Name:     user.Name,
-			Username: user.Username,
-			Email:    user.Email,
+		var boundary models.Post
+		for _, post := range posts {
+			if boundary.ID == 0 || post.UpdatedAt.Before(boundary.UpdatedAt) {
+				boundary = post
+			}
 		}
 
-		post.Comments[i].User = u
+		candidates := lo.Filter(posts, func(post models.Post, _ int) bool {
+			return cursor.Before(post) && (exhausted || !post.CreatedAt.Before(boundary.UpdatedAt))
+		})
+
+		if int32(len(candidates)) > limit || exhausted {
+			sort.Slice(candidates, func(i, j int) bool {
+				return models.CursorOf(candidates[i]).Before(candidates[j])
+			})
+
+			result := models.PostsPage{Posts: candidates[:min(int32(len(candidates)), limit)]}
+			if int32(len(candidates)) > limit {
+				result.NextCursor = models.CursorOf(result.Posts[limit-1]).Encode()
+			} else {
+				result.Truncated = capped
+			}
+
+			return result, nil
+		}
+
+		window = min(window*2, maxPostsWindow)
 	}
+}
 
-	return post, nil
+func pageLimit(page models.PageRequest) int32 {
+	if page.Limit <= 0 {
+		return defaultPageLimit
+	}
+
+	return min(page.Limit, maxPageLimit)
+}
+
+func (s *PostsService) PostByID(ctx context.Context, postID int32, userID int32) (models.Post, error) {
+	if postID == 0 {
+		return models.Post{}, errors.Wrap(models.ErrInvalidArgument, "invalid post id")
+	}
+
+	post, err := s.repo.PostByID(ctx, postID, userID)
+	if err != nil {
+		return models.Post{}, errors.Wrap(err, "posts repo err")
+	}
+
+	posts := []models.Post{post}
+
+	err = s.hydratePosts(ctx, posts, userID)
+	if err != nil {
+		return models.Post{}, err
+	}
+
+	return posts[0], nil
 }
 
 func (s *PostsService) CommentsByPostID(ctx context.Context, postID int32) ([]models.Comment, error) {
@@ -196,9 +672,42 @@ func (s *PostsService) CommentsByPostID(ctx context.Context, postID int32) ([]mo
 		return nil, errors.Wrap(err, "posts repo err")
 	}
 
+	if len(comments) == 0 {
+		return comments, nil
+	}
+
+	userIDs := lo.Uniq(lo.Map(comments, func(comment models.Comment, _ int) int32 {
+		return comment.UserID
+	}))
+
+	usersByID, err := s.usersRepo.FetchUsersByIDs(ctx, userIDs)
+	if err != nil {
+		return nil, errors.Wrap(err, "get users err")
+	}
+
+	for i, comment := range comments {
+		comments[i].User = publicUser(usersByID[comment.UserID])
+	}
+
 	return comments, nil
 }
 
-func NewPostsService(repo PostsRepository, usersRepo PostsUsersByIDsRepository) *PostsService {
-	return &PostsService{repo: repo, usersRepo: usersRepo}
+func NewPostsService(
+	repo PostsRepository,
+	usersRepo PostsUsersByIDsRepository,
+	verification EmailVerificationChecker,
+	restrictions PostingRestrictionRepository,
+	replies ReplyRepository,
+	reposts RepostRepository,
+	hashtags HashtagRepository,
+) *PostsService {
+	return &PostsService{
+		repo:         repo,
+		usersRepo:    usersRepo,
+		verification: verification,
+		restrictions: restrictions,
+		replies:      replies,
+		reposts:      reposts,
+		hashtags:     hashtags,
+	}
 }
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"sort"
+	"time"
+	"twitter-bff/domain/models"
+)
+
+// SessionRepository хранит сессии рядом с refresh токенами: отзыв семейства или всех токенов
+// пользователя удаляет и соответствующие сессии
+type SessionRepository interface {
+	SaveSession(ctx context.Context, session models.Session) error
+	// TouchSession обновляет адрес и время последней активности и продлевает сессию
+	TouchSession(ctx context.Context, id string, client models.ClientInfo, lastSeenAt, expiredAt time.Time) error
+	FetchSession(ctx context.Context, id string) (models.Session, error)
+	ListSessions(ctx context.Context, userID int32) ([]models.Session, error)
+}
+
+type SessionsService struct {
+	repo        SessionRepository
+	refreshRepo LogoutRefreshTokenRepository
+	revocations RevocationRepository
+	config      Config
+}
+
+// List возвращает активные сессии пользователя, последние использованные первыми.
+// Время последней активности обновляется при входе и обновлении токенов.
+func (s *SessionsService) List(ctx context.Context, userID int32) ([]models.Session, error) {
+	sessions, err := s.repo.ListSessions(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "session repo err")
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt)
+	})
+
+	return sessions, nil
+}
+
+// Revoke завершает одну сессию пользователя. Чужая сессия не отличается от несуществующей.
+func (s *SessionsService) Revoke(ctx context.Context, userID int32, sessionID string) error {
+	session, err := s.repo.FetchSession(ctx, sessionID)
+	if errors.Is(err, models.ErrNotFound) || (err == nil && session.UserID != userID) {
+		return errors.Wrap(models.ErrNotFound, "session not found")
+	}
+	if err != nil {
+		return errors.Wrap(err, "session repo err")
+	}
+
+	return s.revoke(ctx, session.ID)
+}
+
+// RevokeOthers завершает все сессии пользователя, кроме текущей
+func (s *SessionsService) RevokeOthers(ctx context.Context, jUser models.JWTUser) error {
+	sessions, err := s.repo.ListSessions(ctx, jUser.UserID)
+	if err != nil {
+		return errors.Wrap(err, "session repo err")
+	}
+
+	for _, session := range sessions {
+		if session.ID == jUser.SessionID {
+			continue
+		}
+
+		err = s.revoke(ctx, session.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// revoke удаляет refresh токены сессии и отзывает ее еще не истекшие access токены
+func (s *SessionsService) revoke(ctx context.Context, sessionID string) error {
+	err := s.refreshRepo.RevokeFamily(ctx, sessionID)
+	if err != nil {
+		return errors.Wrap(err, "refresh token repo err")
+	}
+
+	err = s.revocations.RevokeSession(ctx, sessionID, time.Now().Add(s.config.AccessTokenTTL))
+	if err != nil {
+		return errors.Wrap(err, "revocation repo err")
+	}
+
+	return nil
+}
+
+func NewSessionsService(
+	repo SessionRepository,
+	refreshRepo LogoutRefreshTokenRepository,
+	revocations RevocationRepository,
+	c Config,
+) *SessionsService {
+	if c.AccessTokenTTL == 0 {
+		c.AccessTokenTTL = defaultAccessTokenTTL
+	}
+
+	return &SessionsService{
+		repo:        repo,
+		refreshRepo: refreshRepo,
+		revocations: revocations,
+		config:      c,
+	}
+}
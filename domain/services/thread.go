@@ -0,0 +1,243 @@
+package services
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"slices"
+	"twitter-bff/domain/models"
+)
+
+const (
+	// maxThreadAncestors сколько предков поста показывается в ветке
+	maxThreadAncestors = 20
+	// maxThreadDepth глубина вложенных ответов, прямые ответы - первый уровень
+	maxThreadDepth = 2
+	// maxThreadRepliesLimit сколько прямых ответов помещается на страницу ветки
+	maxThreadRepliesLimit = 20
+	// nestedRepliesLimit сколько вложенных ответов показывается под каждым ответом
+	nestedRepliesLimit = 3
+)
+
+// Thread собирает ветку обсуждения: цепочку предков поста и страницу прямых ответов
+// с вложенными ответами, не больше 20 предков и 20 + 20*3 ответов. Пост с предками загружается
+// одной пачкой, ответы - пачкой на каждый уровень. Авторы, цитаты и данные индекса загружаются
+// один раз для всех постов ветки. Удаленные посты в ветке пропускаются.
+func (s *PostsService) Thread(ctx context.Context, postID, userID int32, page models.PageRequest) (models.Thread, error) {
+	if postID == 0 {
+		return models.Thread{}, errors.Wrap(models.ErrInvalidArgument, "invalid post id")
+	}
+
+	cursor, err := models.DecodePostCursor(page.Cursor)
+	if err != nil {
+		return models.Thread{}, err
+	}
+
+	post, ancestors, err := s.threadPost(ctx, postID, userID)
+	if err != nil {
+		return models.Thread{}, err
+	}
+
+	replyIDs, err := s.replies.Replies(ctx, postID)
+	if err != nil {
+		return models.Thread{}, errors.Wrap(err, "reply repo err")
+	}
+
+	// ответы хранятся в порядке создания, а ID в twitter-posts растут вместе с ним,
+	// поэтому для продолжения страницы достаточно ID из курсора
+	replyIDs = lo.Filter(replyIDs, func(id int32, _ int) bool {
+		return cursor.IsZero() || id > cursor.ID
+	})
+
+	replies, more, err := s.threadReplies(ctx, replyIDs, min(pageLimit(page), maxThreadRepliesLimit), userID)
+	if err != nil {
+		return models.Thread{}, err
+	}
+
+	posts := append(ancestors, post)
+	posts = collectReplyPosts(posts, replies)
+
+	err = s.hydratePosts(ctx, posts, userID)
+	if err != nil {
+		return models.Thread{}, err
+	}
+
+	thread := models.Thread{
+		Ancestors: posts[:len(ancestors)],
+		Post:      posts[len(ancestors)],
+		Replies:   replies,
+	}
+
+	fillReplyPosts(thread.Replies, posts[len(ancestors)+1:])
+
+	if more {
+		thread.NextCursor = models.CursorOf(replies[len(replies)-1].Post).Encode()
+	}
+
+	return thread, nil
+}
+
+// threadPost загружает пост и его предков от корня одной пачкой, без дополнения. Цепочку знает
+// индекс ответов, она обрывается на первом удаленном предке.
+func (s *PostsService) threadPost(ctx context.Context, postID, userID int32) (models.Post, []models.Post, error) {
+	ids := []int32{postID}
+
+	for childID := postID; len(ids) <= maxThreadAncestors; {
+		parents, err := s.replies.Parents(ctx, []int32{childID})
+		if err != nil {
+			return models.Post{}, nil, errors.Wrap(err, "reply repo err")
+		}
+
+		parentID := parents[childID]
+		if parentID == 0 {
+			break
+		}
+
+		ids = append(ids, parentID)
+		childID = parentID
+	}
+
+	found, err := s.repo.PostsByIDs(ctx, ids, userID)
+	if err != nil {
+		return models.Post{}, nil, errors.Wrap(err, "posts repo err")
+	}
+
+	post, ok := found[postID]
+	if !ok {
+		return models.Post{}, nil, errors.Wrap(models.ErrNotFound, "post not found")
+	}
+
+	ancestors := make([]models.Post, 0, len(ids)-1)
+	for _, id := range ids[1:] {
+		parent, ok := found[id]
+		if !ok {
+			break
+		}
+
+		ancestors = append(ancestors, parent)
+	}
+
+	slices.Reverse(ancestors)
+
+	return post, ancestors, nil
+}
+
+// threadReplies загружает до limit ответов из replyIDs и вложенные ответы до maxThreadDepth без
+// дополнения, его делает Thread для всей ветки. more сообщает, что в replyIDs остались непросмотренные ответы.
+func (s *PostsService) threadReplies(ctx context.Context, replyIDs []int32, limit, userID int32) ([]models.ThreadReply, bool, error) {
+	loaded, more, err := s.loadReplyPosts(ctx, [][]int32{replyIDs}, int(limit), userID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	replies := newThreadReplies(loaded[0])
+
+	level := make([]*models.ThreadReply, 0, len(replies))
+	for i := range replies {
+		level = append(level, &replies[i])
+	}
+
+	for depth := 1; depth < maxThreadDepth && len(level) > 0; depth++ {
+		nestedIDs := make([][]int32, 0, len(level))
+		for _, reply := range level {
+			ids, err := s.replies.Replies(ctx, reply.Post.ID)
+			if err != nil {
+				return nil, false, errors.Wrap(err, "reply repo err")
+			}
+
+			nestedIDs = append(nestedIDs, ids)
+		}
+
+		nested, _, err := s.loadReplyPosts(ctx, nestedIDs, nestedRepliesLimit, userID)
+		if err != nil {
+			return nil, false, err
+		}
+
+		next := make([]*models.ThreadReply, 0)
+		for i, reply := range level {
+			reply.Replies = newThreadReplies(nested[i])
+
+			for j := range reply.Replies {
+				next = append(next, &reply.Replies[j])
+			}
+		}
+
+		level = next
+	}
+
+	return replies, more[0], nil
+}
+
+// loadReplyPosts загружает из каждого списка ID до limit существующих постов одной пачкой на все списки.
+// На место удаленных постов догружаются следующие ID, так что вторая пачка нужна, только если среди
+// ответов есть удаленные. more[i] сообщает, что в i-м списке остались незагруженные ID.
+func (s *PostsService) loadReplyPosts(
+	ctx context.Context,
+	lists [][]int32,
+	limit int,
+	userID int32,
+) ([][]models.Post, []bool, error) {
+	posts := make([][]models.Post, len(lists))
+	next := make([]int, len(lists))
+
+	for {
+		batch := make([]int32, 0)
+		for i, ids := range lists {
+			batch = append(batch, ids[next[i]:min(len(ids), next[i]+limit-len(posts[i]))]...)
+		}
+
+		if len(batch) == 0 {
+			break
+		}
+
+		found, err := s.repo.PostsByIDs(ctx, batch, userID)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "posts repo err")
+		}
+
+		for i, ids := range lists {
+			end := min(len(ids), next[i]+limit-len(posts[i]))
+
+			for _, id := range ids[next[i]:end] {
+				if post, ok := found[id]; ok {
+					posts[i] = append(posts[i], post)
+				}
+			}
+
+			next[i] = end
+		}
+	}
+
+	more := make([]bool, len(lists))
+	for i, ids := range lists {
+		more[i] = next[i] < len(ids)
+	}
+
+	return posts, more, nil
+}
+
+func newThreadReplies(posts []models.Post) []models.ThreadReply {
+	return lo.Map(posts, func(post models.Post, _ int) models.ThreadReply {
+		return models.ThreadReply{Post: post, Replies: make([]models.ThreadReply, 0)}
+	})
+}
+
+// collectReplyPosts добавляет посты ответов в posts в прямом порядке обхода дерева
+func collectReplyPosts(posts []models.Post, replies []models.ThreadReply) []models.Post {
+	for _, reply := range replies {
+		posts = append(posts, reply.Post)
+		posts = collectReplyPosts(posts, reply.Replies)
+	}
+
+	return posts
+}
+
+// fillReplyPosts возвращает в дерево посты, собранные collectReplyPosts, и отдает непрочитанный остаток
+func fillReplyPosts(replies []models.ThreadReply, posts []models.Post) []models.Post {
+	for i := range replies {
+		replies[i].Post = posts[0]
+		posts = fillReplyPosts(replies[i].Replies, posts[1:])
+	}
+
+	return posts
+}
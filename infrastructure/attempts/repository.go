@@ -0,0 +1,88 @@
+package attempts
+
+import (
+	"context"
+	"sync"
+	"time"
+	"twitter-bff/domain/models"
+)
+
+// Repository счетчики попыток и блокировки по ключу. Рестарт обнуляет счетчики и снимает
+// блокировки: перебор получает не больше одного лишнего окна.
+type Repository struct {
+	mu       sync.Mutex
+	attempts map[string]models.LoginAttempts
+}
+
+// Increment атомарно проверяет блокировку и учитывает попытку. Если последняя попытка была
+// раньше окна window, счет начинается заново. Пока ключ заблокирован, попытки не учитываются.
+func (r *Repository) Increment(
+	_ context.Context,
+	key string,
+	window time.Duration,
+	lockFor func(failures int) time.Duration,
+) (models.LoginAttempts, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	r.evictExpired(now, window)
+
+	attempts := r.attempts[key]
+	if now.Before(attempts.LockedUntil) {
+		return attempts, false, nil
+	}
+
+	if now.Sub(attempts.LastFailedAt) > window {
+		attempts.Failures = 0
+	}
+
+	attempts.Failures++
+	attempts.LastFailedAt = now
+
+	if lock := lockFor(attempts.Failures); lock > 0 {
+		attempts.LockedUntil = now.Add(lock)
+	}
+
+	r.attempts[key] = attempts
+
+	return attempts, true, nil
+}
+
+func (r *Repository) Release(_ context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attempts, ok := r.attempts[key]
+	if !ok || attempts.Failures == 0 {
+		return nil
+	}
+
+	attempts.Failures--
+	r.attempts[key] = attempts
+
+	return nil
+}
+
+func (r *Repository) Reset(_ context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.attempts, key)
+
+	return nil
+}
+
+func (r *Repository) evictExpired(now time.Time, window time.Duration) {
+	for key, attempts := range r.attempts {
+		if now.Sub(attempts.LastFailedAt) > window && now.After(attempts.LockedUntil) {
+			delete(r.attempts, key)
+		}
+	}
+}
+
+func NewRepository() *Repository {
+	return &Repository{
+		attempts: make(map[string]models.LoginAttempts),
+	}
+}
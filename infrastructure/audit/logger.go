@@ -0,0 +1,69 @@
+package audit
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"twitter-bff/domain/models"
+)
+
+type Config struct {
+	// File файл журнала в формате JSON lines, пусто - журнал пишется в stderr
+	File string
+}
+
+// Logger пишет журнал действий администраторов через отдельный zap логгер
+type Logger struct {
+	logger *zap.Logger
+}
+
+func (l *Logger) Record(_ context.Context, entry models.AuditEntry) error {
+	fields := []zap.Field{
+		zap.Time("time", entry.Time),
+		zap.String("action", entry.Action),
+		zap.Int32("actorId", entry.ActorID),
+		zap.Int32("userId", entry.UserID),
+		zap.String("ip", entry.IP),
+	}
+
+	if len(entry.Method) != 0 {
+		fields = append(fields, zap.String("method", entry.Method), zap.String("path", entry.Path), zap.Int("status", entry.Status))
+	}
+
+	if len(entry.Reason) != 0 {
+		fields = append(fields, zap.String("reason", entry.Reason))
+	}
+
+	l.logger.Info("audit", fields...)
+
+	return nil
+}
+
+func (l *Logger) OnStop(_ context.Context) error {
+	// ошибку Sync для stderr и stdout возвращают многие системы, она не означает потерю записей
+	_ = l.logger.Sync()
+
+	return nil
+}
+
+// NewLogger строит логгер журнала отдельно от логгера приложения даже без файла:
+// у логгера приложения включен sampling, и часть записей журнала пропадала бы
+func NewLogger(c Config) (*Logger, error) {
+	zapConfig := zap.NewProductionConfig()
+	zapConfig.OutputPaths = []string{"stderr"}
+	if len(c.File) != 0 {
+		zapConfig.OutputPaths = []string{c.File}
+	}
+
+	// журнал не должен терять записи
+	zapConfig.Sampling = nil
+	zapConfig.DisableCaller = true
+	zapConfig.DisableStacktrace = true
+
+	auditLogger, err := zapConfig.Build()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open audit log")
+	}
+
+	return &Logger{logger: auditLogger.Named("audit")}, nil
+}
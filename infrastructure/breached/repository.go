@@ -0,0 +1,75 @@
+package breached
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const prefixLength = 5
+
+type Config struct {
+	// Dir каталог с файлами в формате k-anonymity (как отдает api.pwnedpasswords.com/range):
+	// имя файла - первые 5 символов SHA-1 в верхнем регистре, строки - "ОСТАТОК_ХЕША:ЧИСЛО".
+	Dir string
+}
+
+// Repository проверяет пароль по локальной копии базы утекших паролей. Читается только
+// файл нужного префикса, поэтому вся база (десятки гигабайт) в память не загружается.
+type Repository struct {
+	dir string
+}
+
+func (r *Repository) IsBreached(_ context.Context, password string) (bool, error) {
+	if len(r.dir) == 0 {
+		return false, nil
+	}
+
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:prefixLength], hash[prefixLength:]
+
+	file, err := os.Open(filepath.Join(r.dir, prefix))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrap(err, "open breached passwords file")
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if strings.EqualFold(line, suffix) {
+			return true, nil
+		}
+	}
+
+	return false, errors.Wrap(scanner.Err(), "read breached passwords file")
+}
+
+func NewRepository(c Config, logger *zap.Logger) (*Repository, error) {
+	if len(c.Dir) == 0 {
+		logger.Warn("breached passwords dir is not configured, check is disabled")
+
+		return &Repository{}, nil
+	}
+
+	info, err := os.Stat(c.Dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "breached passwords dir")
+	}
+
+	if !info.IsDir() {
+		return nil, errors.Errorf("breached passwords path %q is not a directory", c.Dir)
+	}
+
+	return &Repository{dir: c.Dir}, nil
+}
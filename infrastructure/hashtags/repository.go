@@ -0,0 +1,158 @@
+package hashtags
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"sort"
+	"strings"
+	"sync"
+	"twitter-bff/domain/models"
+	"twitter-bff/pkg/filestore"
+)
+
+type Config struct {
+	// File JSON файл с тегами постов
+	File string
+}
+
+// tagged теги поста и его позиция в ленте, в таком виде пост лежит в файле
+type tagged struct {
+	Post models.PostCursor
+	Tags []string
+}
+
+// Repository индекс хэштегов, который строится при публикации постов: twitter-posts о тегах не знает.
+// Индекс хранится в файле, иначе после рестарта поиск по тегам видел бы только новые посты.
+type Repository struct {
+	mu sync.RWMutex
+	// posts позиции постов с тегом, строится по tagged
+	posts map[string]map[int32]models.PostCursor
+	// tagged теги каждого поста, чтобы убрать его из индекса при замене тегов
+	tagged map[int32]tagged
+	file   *filestore.File[map[int32]tagged]
+}
+
+// SaveHashtags заменяет теги поста
+func (r *Repository) SaveHashtags(_ context.Context, post models.PostCursor, tags []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous, ok := r.tagged[post.ID]
+	if !ok && len(tags) == 0 {
+		return nil
+	}
+
+	r.deleteHashtags(post.ID)
+	r.addHashtags(tagged{Post: post, Tags: tags})
+
+	err := r.file.Save(r.tagged)
+	if err != nil {
+		r.deleteHashtags(post.ID)
+		if ok {
+			r.addHashtags(previous)
+		}
+
+		return errors.Wrap(err, "save hashtags")
+	}
+
+	return nil
+}
+
+func (r *Repository) addHashtags(post tagged) {
+	if len(post.Tags) == 0 {
+		return
+	}
+
+	for _, tag := range post.Tags {
+		if r.posts[tag] == nil {
+			r.posts[tag] = make(map[int32]models.PostCursor)
+		}
+
+		r.posts[tag][post.Post.ID] = post.Post
+	}
+
+	r.tagged[post.Post.ID] = post
+}
+
+func (r *Repository) deleteHashtags(postID int32) {
+	for _, tag := range r.tagged[postID].Tags {
+		delete(r.posts[tag], postID)
+
+		if len(r.posts[tag]) == 0 {
+			delete(r.posts, tag)
+		}
+	}
+
+	delete(r.tagged, postID)
+}
+
+// PostsByHashtag позиции постов с тегом после курсора, от новых к старым
+func (r *Repository) PostsByHashtag(
+	_ context.Context,
+	tag string,
+	cursor models.PostCursor,
+	limit int32,
+) ([]models.PostCursor, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	positions := make([]models.PostCursor, 0, len(r.posts[tag]))
+	for _, position := range r.posts[tag] {
+		if cursor.Precedes(position) {
+			positions = append(positions, position)
+		}
+	}
+
+	sort.Slice(positions, func(i, j int) bool {
+		return positions[i].Precedes(positions[j])
+	})
+
+	return positions[:min(len(positions), int(limit))], nil
+}
+
+// SearchHashtags теги, начинающиеся с prefix: сначала самые популярные
+func (r *Repository) SearchHashtags(_ context.Context, prefix string, limit int32) ([]models.Hashtag, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	hashtags := make([]models.Hashtag, 0)
+	for tag, posts := range r.posts {
+		if strings.HasPrefix(tag, prefix) {
+			hashtags = append(hashtags, models.Hashtag{Tag: tag, PostCount: int32(len(posts))})
+		}
+	}
+
+	sort.Slice(hashtags, func(i, j int) bool {
+		if hashtags[i].PostCount != hashtags[j].PostCount {
+			return hashtags[i].PostCount > hashtags[j].PostCount
+		}
+
+		return hashtags[i].Tag < hashtags[j].Tag
+	})
+
+	return hashtags[:min(len(hashtags), int(limit))], nil
+}
+
+func NewRepository(c Config) (*Repository, error) {
+	file, err := filestore.New[map[int32]tagged](c.File)
+	if err != nil {
+		return nil, errors.Wrap(err, "hashtag store")
+	}
+
+	saved, err := file.Load()
+	if err != nil {
+		return nil, errors.Wrap(err, "hashtag store")
+	}
+
+	r := &Repository{
+		posts:  make(map[string]map[int32]models.PostCursor),
+		tagged: make(map[int32]tagged, len(saved)),
+		file:   file,
+	}
+
+	for _, post := range saved {
+		r.addHashtags(post)
+	}
+
+	return r, nil
+}
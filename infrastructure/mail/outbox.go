@@ -0,0 +1,44 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"os"
+	"path/filepath"
+	"time"
+	"twitter-bff/domain/models"
+)
+
+const outboxFrom = "twitter-bff@localhost"
+
+// OutboxMailer складывает письма .eml файлами в каталог вместо отправки.
+// Нужен для локальной разработки и тестов: ссылку из письма можно взять из файла.
+type OutboxMailer struct {
+	dir string
+}
+
+func (m *OutboxMailer) Send(_ context.Context, mail models.Mail) error {
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405"), uuid.NewString())
+
+	err := os.WriteFile(filepath.Join(m.dir, name), message(outboxFrom, mail), 0o600)
+	if err != nil {
+		return errors.Wrap(err, "write outbox mail")
+	}
+
+	return nil
+}
+
+func NewOutboxMailer(dir string) (*OutboxMailer, error) {
+	if len(dir) == 0 {
+		dir = filepath.Join(os.TempDir(), "twitter-bff-outbox")
+	}
+
+	err := os.MkdirAll(dir, 0o700)
+	if err != nil {
+		return nil, errors.Wrap(err, "create outbox dir")
+	}
+
+	return &OutboxMailer{dir: dir}, nil
+}
@@ -0,0 +1,70 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+	"twitter-bff/domain/models"
+)
+
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// SMTPMailer отправляет письма через SMTP сервер. Если сервер поддерживает STARTTLS,
+// net/smtp включает его сам; PLAIN аутентификация вне TLS запрещена net/smtp.
+type SMTPMailer struct {
+	config SMTPConfig
+}
+
+func (m *SMTPMailer) Send(_ context.Context, mail models.Mail) error {
+	addr := net.JoinHostPort(m.config.Host, strconv.Itoa(m.config.Port))
+
+	var auth smtp.Auth
+	if len(m.config.Username) != 0 {
+		auth = smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)
+	}
+
+	err := smtp.SendMail(addr, auth, m.config.From, []string{mail.To}, message(m.config.From, mail))
+	if err != nil {
+		return errors.Wrap(err, "smtp send")
+	}
+
+	return nil
+}
+
+func message(from string, mail models.Mail) []byte {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", mail.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mail.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(mail.Body, "\n", "\r\n"))
+
+	return []byte(b.String())
+}
+
+func NewSMTPMailer(c SMTPConfig) (*SMTPMailer, error) {
+	if len(c.Host) == 0 || len(c.From) == 0 {
+		return nil, errors.New("smtp host and from are required")
+	}
+
+	if c.Port == 0 {
+		c.Port = 587
+	}
+
+	return &SMTPMailer{config: c}, nil
+}
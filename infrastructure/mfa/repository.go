@@ -0,0 +1,174 @@
+package mfa
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"slices"
+	"sync"
+	"time"
+	"twitter-bff/domain/models"
+	"twitter-bff/pkg/filestore"
+)
+
+type Config struct {
+	// File JSON файл с факторами, обязателен: без него сервис не стартует. Содержит TOTP секреты,
+	// доступ к нему равен доступу ко второму фактору.
+	File string
+}
+
+// Repository хранит вторые факторы в файле: в twitter-users для них нет места, а потеря факторов
+// при рестарте незаметно отключила бы 2FA всем пользователям. Использованные mfa токены живут
+// минуты и хранятся только в памяти.
+type Repository struct {
+	mu         sync.Mutex
+	factors    map[int32]models.TOTPFactor
+	file       *filestore.File[map[int32]models.TOTPFactor]
+	challenges map[string]time.Time
+}
+
+func (r *Repository) Fetch(_ context.Context, userID int32) (models.TOTPFactor, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	factor, ok := r.factors[userID]
+	if !ok {
+		return models.TOTPFactor{}, models.ErrNotFound
+	}
+
+	factor.RecoveryCodeHashes = slices.Clone(factor.RecoveryCodeHashes)
+
+	return factor, nil
+}
+
+func (r *Repository) Save(_ context.Context, factor models.TOTPFactor) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	factor.RecoveryCodeHashes = slices.Clone(factor.RecoveryCodeHashes)
+
+	return r.store(factor.UserID, &factor)
+}
+
+func (r *Repository) Delete(_ context.Context, userID int32) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.store(userID, nil)
+}
+
+func (r *Repository) IsEnabled(_ context.Context, userID int32) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.factors[userID].Confirmed, nil
+}
+
+func (r *Repository) UseStep(_ context.Context, userID int32, step int64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	factor, ok := r.factors[userID]
+	if !ok || step <= factor.LastUsedStep {
+		return false, nil
+	}
+
+	factor.LastUsedStep = step
+
+	err := r.store(userID, &factor)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *Repository) ConsumeRecoveryCode(_ context.Context, userID int32, hash string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	factor, ok := r.factors[userID]
+	if !ok {
+		return false, nil
+	}
+
+	i := slices.Index(factor.RecoveryCodeHashes, hash)
+	if i < 0 {
+		return false, nil
+	}
+
+	factor.RecoveryCodeHashes = slices.Delete(slices.Clone(factor.RecoveryCodeHashes), i, i+1)
+
+	err := r.store(userID, &factor)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *Repository) MarkChallengeUsed(_ context.Context, tokenID string, expiredAt time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for id, exp := range r.challenges {
+		if now.After(exp) {
+			delete(r.challenges, id)
+		}
+	}
+
+	if _, ok := r.challenges[tokenID]; ok {
+		return false, nil
+	}
+
+	r.challenges[tokenID] = expiredAt
+
+	return true, nil
+}
+
+// store заменяет фактор пользователя (nil - удаляет) и перезаписывает файл.
+// Если файл записать не удалось, изменение откатывается.
+func (r *Repository) store(userID int32, factor *models.TOTPFactor) error {
+	previous, existed := r.factors[userID]
+
+	if factor != nil {
+		r.factors[userID] = *factor
+	} else {
+		delete(r.factors, userID)
+	}
+
+	err := r.file.Save(r.factors)
+	if err == nil {
+		return nil
+	}
+
+	if existed {
+		r.factors[userID] = previous
+	} else {
+		delete(r.factors, userID)
+	}
+
+	return errors.Wrap(err, "save mfa factors")
+}
+
+func NewRepository(c Config) (*Repository, error) {
+	file, err := filestore.New[map[int32]models.TOTPFactor](c.File)
+	if err != nil {
+		return nil, errors.Wrap(err, "mfa store")
+	}
+
+	factors, err := file.Load()
+	if err != nil {
+		return nil, errors.Wrap(err, "mfa store")
+	}
+
+	if factors == nil {
+		factors = make(map[int32]models.TOTPFactor)
+	}
+
+	return &Repository{
+		factors:    factors,
+		file:       file,
+		challenges: make(map[string]time.Time),
+	}, nil
+}
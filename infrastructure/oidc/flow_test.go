@@ -0,0 +1,512 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pkg/errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+	"twitter-bff/domain/models"
+	"twitter-bff/domain/services"
+)
+
+const (
+	testProvider     = "mock"
+	testClientID     = "twitter-bff"
+	testClientSecret = "secret"
+	testRedirectURL  = "http://localhost:8080/api/v1/oauth/mock/callback"
+)
+
+// mockIdP провайдер с discovery, JWKS, страницей входа и token endpoint, который проверяет PKCE
+type mockIdP struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu sync.Mutex
+	// user кого провайдер "залогинит" на странице входа
+	user jwt.MapClaims
+	// codes выданные коды: одноразовые, привязаны к challenge и nonce
+	codes map[string]mockAuthCode
+	// signKey ключ подписи ID токена, по умолчанию key из JWKS
+	signKey *rsa.PrivateKey
+	// tamper правит claims ID токена перед подписью
+	tamper func(claims jwt.MapClaims)
+}
+
+type mockAuthCode struct {
+	challenge   string
+	nonce       string
+	redirectURI string
+	user        jwt.MapClaims
+}
+
+func newMockIdP(t *testing.T) *mockIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idp := &mockIdP{t: t, key: key, codes: make(map[string]mockAuthCode)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", idp.discovery)
+	mux.HandleFunc("/jwks", idp.jwks)
+	mux.HandleFunc("/authorize", idp.authorize)
+	mux.HandleFunc("/token", idp.token)
+
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+
+	return idp
+}
+
+func (idp *mockIdP) discovery(w http.ResponseWriter, _ *http.Request) {
+	issuer := idp.server.URL
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                issuer,
+		"authorization_endpoint":                issuer + "/authorize",
+		"token_endpoint":                        issuer + "/token",
+		"jwks_uri":                              issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (idp *mockIdP) jwks(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "test",
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(idp.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(idp.key.E)).Bytes()),
+		}},
+	})
+}
+
+// authorize страница входа: сразу "логинит" idp.user и возвращает код на redirect_uri
+func (idp *mockIdP) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	if query.Get("response_type") != "code" || query.Get("client_id") != testClientID ||
+		query.Get("code_challenge_method") != "S256" || len(query.Get("code_challenge")) == 0 {
+		http.Error(w, "invalid authorization request", http.StatusBadRequest)
+		return
+	}
+
+	idp.mu.Lock()
+	code := fmt.Sprintf("code-%d", len(idp.codes)+1)
+	idp.codes[code] = mockAuthCode{
+		challenge:   query.Get("code_challenge"),
+		nonce:       query.Get("nonce"),
+		redirectURI: query.Get("redirect_uri"),
+		user:        idp.user,
+	}
+	idp.mu.Unlock()
+
+	redirect, _ := url.Parse(query.Get("redirect_uri"))
+	redirect.RawQuery = url.Values{"code": {code}, "state": {query.Get("state")}}.Encode()
+
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (idp *mockIdP) token(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientID != testClientID || clientSecret != testClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	idp.mu.Lock()
+	code, ok := idp.codes[r.PostForm.Get("code")]
+	delete(idp.codes, r.PostForm.Get("code"))
+	signKey, tamper := idp.signKey, idp.tamper
+	idp.mu.Unlock()
+
+	verifier := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || r.PostForm.Get("grant_type") != "authorization_code" ||
+		r.PostForm.Get("redirect_uri") != code.redirectURI ||
+		base64.RawURLEncoding.EncodeToString(verifier[:]) != code.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":   idp.server.URL,
+		"aud":   testClientID,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Minute).Unix(),
+		"nonce": code.nonce,
+	}
+	for k, v := range code.user {
+		claims[k] = v
+	}
+	if tamper != nil {
+		tamper(claims)
+	}
+
+	if signKey == nil {
+		signKey = idp.key
+	}
+
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	idToken.Header["kid"] = "test"
+
+	rawIDToken, err := idToken.SignedString(signKey)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": "access",
+		"token_type":   "Bearer",
+		"expires_in":   60,
+		"id_token":     rawIDToken,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+type fakeUsers struct {
+	users map[string]models.User
+}
+
+func (r *fakeUsers) FetchUserByEmail(_ context.Context, email string) (models.User, error) {
+	user, ok := r.users[email]
+	if !ok {
+		return models.User{}, models.ErrNotFound
+	}
+
+	return user, nil
+}
+
+func (r *fakeUsers) Create(_ context.Context, name, passwordHash, username, email string) (models.User, error) {
+	user := models.User{ID: int32(len(r.users) + 1), Name: name, PasswordHash: passwordHash, Username: username, Email: email}
+	r.users[email] = user
+
+	return user, nil
+}
+
+type fakeVerification struct {
+	verified map[int32]string
+}
+
+func (r *fakeVerification) MarkVerified(_ context.Context, userID int32, email string) error {
+	r.verified[userID] = email
+	return nil
+}
+
+func (r *fakeVerification) IsVerified(_ context.Context, userID int32, email string) (bool, error) {
+	return r.verified[userID] == email, nil
+}
+
+type fakeSessions struct {
+	userIDs []int32
+}
+
+func (s *fakeSessions) StartSession(_ context.Context, userID int32, _ models.ClientInfo) (models.LoginResult, error) {
+	s.userIDs = append(s.userIDs, userID)
+	return models.LoginResult{}, nil
+}
+
+type flowEnv struct {
+	idp          *mockIdP
+	svc          *services.OIDCService
+	identities   *IdentityRepository
+	users        *fakeUsers
+	verification *fakeVerification
+	sessions     *fakeSessions
+}
+
+func newFlowEnv(t *testing.T) *flowEnv {
+	idp := newMockIdP(t)
+
+	client, err := NewClient(Config{Providers: []ProviderConfig{{
+		Name:         testProvider,
+		Issuer:       idp.server.URL,
+		ClientID:     testClientID,
+		ClientSecret: testClientSecret,
+		RedirectURL:  testRedirectURL,
+	}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stores := Config{
+		AuthRequestsFile: filepath.Join(t.TempDir(), "auth-requests.json"),
+		IdentitiesFile:   filepath.Join(t.TempDir(), "identities.json"),
+	}
+
+	requests, err := NewAuthRequestRepository(stores)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	identities, err := NewIdentityRepository(stores)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	env := &flowEnv{
+		idp:          idp,
+		identities:   identities,
+		users:        &fakeUsers{users: make(map[string]models.User)},
+		verification: &fakeVerification{verified: make(map[int32]string)},
+		sessions:     &fakeSessions{},
+	}
+	env.svc = services.NewOIDCService(
+		client,
+		requests,
+		env.identities,
+		env.users,
+		env.verification,
+		env.sessions,
+		services.Config{},
+	)
+
+	return env
+}
+
+// login проходит вход как браузер: authorize, страница провайдера, callback с cookie state
+func (e *flowEnv) login(t *testing.T, user jwt.MapClaims) error {
+	ctx := context.Background()
+
+	e.idp.mu.Lock()
+	e.idp.user = user
+	e.idp.mu.Unlock()
+
+	authURL, browserState, err := e.svc.Authorize(ctx, testProvider)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state, code := e.visitProvider(t, authURL)
+
+	_, err = e.svc.Callback(ctx, testProvider, state, browserState, code, models.ClientInfo{})
+
+	return err
+}
+
+// visitProvider открывает страницу провайдера и возвращает state и code из редиректа на callback
+func (e *flowEnv) visitProvider(t *testing.T, authURL string) (string, string) {
+	httpClient := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+
+	resp, err := httpClient.Get(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("authorize: status %d", resp.StatusCode)
+	}
+
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := callback.Scheme + "://" + callback.Host + callback.Path; got != testRedirectURL {
+		t.Fatalf("redirect to %q, want %q", got, testRedirectURL)
+	}
+
+	return callback.Query().Get("state"), callback.Query().Get("code")
+}
+
+func verifiedUser(subject, email string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub":                subject,
+		"email":              email,
+		"email_verified":     true,
+		"name":               "Alice",
+		"preferred_username": "alice",
+	}
+}
+
+func TestFlowCreatesAndLinksNewUser(t *testing.T) {
+	env := newFlowEnv(t)
+
+	err := env.login(t, verifiedUser("sub-1", "alice@example.com"))
+	if err != nil {
+		t.Fatalf("first login: %v", err)
+	}
+
+	user, ok := env.users.users["alice@example.com"]
+	if !ok {
+		t.Fatal("user was not created")
+	}
+	if user.Username != "alice" || user.Name != "Alice" {
+		t.Fatalf("unexpected user %+v", user)
+	}
+	if env.verification.verified[user.ID] != user.Email {
+		t.Fatal("email of the new user is not marked verified")
+	}
+
+	// второй вход находит пользователя по привязанной учетке, даже если email у провайдера сменился
+	err = env.login(t, verifiedUser("sub-1", "alice@other.example"))
+	if err != nil {
+		t.Fatalf("second login: %v", err)
+	}
+
+	if len(env.users.users) != 1 {
+		t.Fatalf("second login created a user: %d users", len(env.users.users))
+	}
+	if len(env.sessions.userIDs) != 2 || env.sessions.userIDs[1] != user.ID {
+		t.Fatalf("sessions started for %v, want two for %d", env.sessions.userIDs, user.ID)
+	}
+}
+
+func TestFlowLinksExistingAccountWithVerifiedEmail(t *testing.T) {
+	env := newFlowEnv(t)
+
+	existing, _ := env.users.Create(context.Background(), "Bob", "hash:pw", "bob", "bob@example.com")
+	env.verification.verified[existing.ID] = existing.Email
+
+	err := env.login(t, verifiedUser("sub-bob", "bob@example.com"))
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+
+	userID, err := env.identities.UserIDBySubject(context.Background(), testProvider, "sub-bob")
+	if err != nil || userID != existing.ID {
+		t.Fatalf("identity linked to %d (%v), want %d", userID, err, existing.ID)
+	}
+}
+
+// Аккаунт с неподтвержденным у нас email мог заранее завести злоумышленник,
+// поэтому вход через провайдера с тем же адресом не должен в него пускать
+func TestFlowRefusesAccountWithUnverifiedEmail(t *testing.T) {
+	env := newFlowEnv(t)
+
+	existing, _ := env.users.Create(context.Background(), "Mallory", "hash:pw", "victim", "victim@example.com")
+
+	err := env.login(t, verifiedUser("sub-victim", "victim@example.com"))
+	if !errors.Is(err, models.ErrOIDCAccountNotVerified) {
+		t.Fatalf("got %v, want ErrOIDCAccountNotVerified", err)
+	}
+
+	_, err = env.identities.UserIDBySubject(context.Background(), testProvider, "sub-victim")
+	if !errors.Is(err, models.ErrNotFound) {
+		t.Fatalf("identity was linked: %v", err)
+	}
+	if _, ok := env.verification.verified[existing.ID]; ok {
+		t.Fatal("unverified account was marked verified")
+	}
+	if len(env.sessions.userIDs) != 0 {
+		t.Fatal("session was started")
+	}
+}
+
+func TestFlowRefusesEmailNotVerifiedByProvider(t *testing.T) {
+	env := newFlowEnv(t)
+
+	user := verifiedUser("sub-2", "carol@example.com")
+	user["email_verified"] = false
+
+	err := env.login(t, user)
+	if !errors.Is(err, models.ErrOIDCEmailNotVerified) {
+		t.Fatalf("got %v, want ErrOIDCEmailNotVerified", err)
+	}
+
+	if len(env.users.users) != 0 {
+		t.Fatal("user was created for an unverified email")
+	}
+}
+
+func TestFlowRejectsForeignBrowserAndReplayedState(t *testing.T) {
+	env := newFlowEnv(t)
+	env.idp.user = verifiedUser("sub-3", "dave@example.com")
+	ctx := context.Background()
+
+	authURL, browserState, err := env.svc.Authorize(ctx, testProvider)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state, code := env.visitProvider(t, authURL)
+
+	_, err = env.svc.Callback(ctx, testProvider, state, "other-browser", code, models.ClientInfo{})
+	if !errors.Is(err, models.ErrInvalidOIDCState) {
+		t.Fatalf("foreign browser: got %v, want ErrInvalidOIDCState", err)
+	}
+
+	_, err = env.svc.Callback(ctx, testProvider, state, browserState, code, models.ClientInfo{})
+	if err != nil {
+		t.Fatalf("callback: %v", err)
+	}
+
+	_, err = env.svc.Callback(ctx, testProvider, state, browserState, code, models.ClientInfo{})
+	if !errors.Is(err, models.ErrInvalidOIDCState) {
+		t.Fatalf("replay: got %v, want ErrInvalidOIDCState", err)
+	}
+}
+
+func TestFlowRejectsInvalidIDToken(t *testing.T) {
+	forgedKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name    string
+		signKey *rsa.PrivateKey
+		tamper  func(claims jwt.MapClaims)
+	}{
+		{name: "nonce mismatch", tamper: func(claims jwt.MapClaims) { claims["nonce"] = "other" }},
+		{name: "foreign audience", tamper: func(claims jwt.MapClaims) { claims["aud"] = "other-client" }},
+		{name: "foreign issuer", tamper: func(claims jwt.MapClaims) { claims["iss"] = "https://evil.example" }},
+		{name: "expired", tamper: func(claims jwt.MapClaims) { claims["exp"] = time.Now().Add(-time.Hour).Unix() }},
+		{name: "signed by unknown key", signKey: forgedKey},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			env := newFlowEnv(t)
+			env.idp.signKey = tc.signKey
+			env.idp.tamper = tc.tamper
+
+			err := env.login(t, verifiedUser("sub-4", "erin@example.com"))
+			if !errors.Is(err, models.ErrInvalidIDToken) {
+				t.Fatalf("got %v, want ErrInvalidIDToken", err)
+			}
+
+			if len(env.users.users) != 0 || len(env.sessions.userIDs) != 0 {
+				t.Fatal("login went through with an invalid id token")
+			}
+		})
+	}
+}
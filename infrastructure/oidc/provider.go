@@ -0,0 +1,165 @@
+package oidc
+
+import (
+	"context"
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+	"sync"
+	"twitter-bff/domain/models"
+)
+
+type ProviderConfig struct {
+	// Name часть пути /v1/oauth/{provider}/...
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL адрес /api/v1/oauth/{provider}/callback, зарегистрированный у провайдера
+	RedirectURL string
+	Scopes      []string
+}
+
+type Config struct {
+	Providers []ProviderConfig
+	// AuthRequestsFile JSON файл с начатыми входами
+	AuthRequestsFile string
+	// IdentitiesFile JSON файл с привязками учеток провайдеров к пользователям
+	IdentitiesFile string
+}
+
+// provider клиент одного провайдера. Discovery выполняется при первом входе, а не на старте,
+// чтобы недоступный провайдер не мешал запуску сервиса.
+type provider struct {
+	config ProviderConfig
+
+	mu       sync.Mutex
+	oauth    *oauth2.Config
+	verifier *oidc.IDTokenVerifier
+}
+
+func (p *provider) authCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error) {
+	oauth, _, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return oauth.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(codeVerifier)), nil
+}
+
+func (p *provider) exchange(ctx context.Context, code, codeVerifier string) (models.OIDCIdentity, error) {
+	oauth, verifier, err := p.discover(ctx)
+	if err != nil {
+		return models.OIDCIdentity{}, err
+	}
+
+	token, err := oauth.Exchange(ctx, code, oauth2.VerifierOption(codeVerifier))
+	if err != nil {
+		return models.OIDCIdentity{}, errors.Wrap(err, "exchange code")
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return models.OIDCIdentity{}, errors.Wrap(models.ErrInvalidIDToken, "no id_token in token response")
+	}
+
+	idToken, err := verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return models.OIDCIdentity{}, errors.Wrap(models.ErrInvalidIDToken, err.Error())
+	}
+
+	var claims struct {
+		Email             string `json:"email"`
+		EmailVerified     bool   `json:"email_verified"`
+		Name              string `json:"name"`
+		PreferredUsername string `json:"preferred_username"`
+	}
+
+	err = idToken.Claims(&claims)
+	if err != nil {
+		return models.OIDCIdentity{}, errors.Wrap(models.ErrInvalidIDToken, err.Error())
+	}
+
+	return models.OIDCIdentity{
+		Provider:          p.config.Name,
+		Subject:           idToken.Subject,
+		Email:             claims.Email,
+		EmailVerified:     claims.EmailVerified,
+		Name:              claims.Name,
+		PreferredUsername: claims.PreferredUsername,
+		Nonce:             idToken.Nonce,
+	}, nil
+}
+
+func (p *provider) discover(ctx context.Context) (*oauth2.Config, *oidc.IDTokenVerifier, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.oauth != nil {
+		return p.oauth, p.verifier, nil
+	}
+
+	// go-oidc отвязывает контекст от отмены, поэтому JWKS продолжит обновляться после запроса
+	discovered, err := oidc.NewProvider(ctx, p.config.Issuer)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "oidc discovery %q", p.config.Name)
+	}
+
+	scopes := p.config.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{oidc.ScopeOpenID, "email", "profile"}
+	}
+
+	p.oauth = &oauth2.Config{
+		ClientID:     p.config.ClientID,
+		ClientSecret: p.config.ClientSecret,
+		RedirectURL:  p.config.RedirectURL,
+		Endpoint:     discovered.Endpoint(),
+		Scopes:       scopes,
+	}
+	p.verifier = discovered.Verifier(&oidc.Config{ClientID: p.config.ClientID})
+
+	return p.oauth, p.verifier, nil
+}
+
+// Client вход через настроенных OIDC провайдеров по authorization code flow с PKCE
+type Client struct {
+	providers map[string]*provider
+}
+
+func (c *Client) AuthCodeURL(ctx context.Context, providerName, state, nonce, codeVerifier string) (string, error) {
+	p, ok := c.providers[providerName]
+	if !ok {
+		return "", models.ErrUnknownOIDCProvider
+	}
+
+	return p.authCodeURL(ctx, state, nonce, codeVerifier)
+}
+
+// Exchange обменивает код на токены и проверяет ID токен: подпись по JWKS провайдера, iss, aud и срок действия
+func (c *Client) Exchange(ctx context.Context, providerName, code, codeVerifier string) (models.OIDCIdentity, error) {
+	p, ok := c.providers[providerName]
+	if !ok {
+		return models.OIDCIdentity{}, models.ErrUnknownOIDCProvider
+	}
+
+	return p.exchange(ctx, code, codeVerifier)
+}
+
+func NewClient(c Config) (*Client, error) {
+	providers := make(map[string]*provider, len(c.Providers))
+
+	for _, pc := range c.Providers {
+		if len(pc.Name) == 0 || len(pc.Issuer) == 0 || len(pc.ClientID) == 0 {
+			return nil, errors.Errorf("oidc provider %q: name, issuer and clientId are required", pc.Name)
+		}
+
+		if _, ok := providers[pc.Name]; ok {
+			return nil, errors.Errorf("duplicate oidc provider %q", pc.Name)
+		}
+
+		providers[pc.Name] = &provider{config: pc}
+	}
+
+	return &Client{providers: providers}, nil
+}
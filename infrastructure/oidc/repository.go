@@ -0,0 +1,182 @@
+package oidc
+
+import (
+	"cmp"
+	"context"
+	"github.com/pkg/errors"
+	"slices"
+	"sync"
+	"time"
+	"twitter-bff/domain/models"
+	"twitter-bff/pkg/filestore"
+)
+
+// AuthRequestRepository начатые входы через провайдера. Запросы хранятся в файле, чтобы вход,
+// начатый до рестарта или деплоя, можно было завершить. Файл содержит nonce и PKCE verifier.
+type AuthRequestRepository struct {
+	mu       sync.Mutex
+	requests map[string]models.OIDCAuthRequest
+	file     *filestore.File[map[string]models.OIDCAuthRequest]
+}
+
+func (r *AuthRequestRepository) Save(_ context.Context, request models.OIDCAuthRequest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for hash, req := range r.requests {
+		if now.After(req.ExpiredAt) {
+			delete(r.requests, hash)
+		}
+	}
+
+	r.requests[request.StateHash] = request
+
+	err := r.file.Save(r.requests)
+	if err != nil {
+		delete(r.requests, request.StateHash)
+
+		return errors.Wrap(err, "save oidc auth requests")
+	}
+
+	return nil
+}
+
+// Consume запрос удаляется из файла до ответа: state нельзя предъявить повторно и после рестарта
+func (r *AuthRequestRepository) Consume(_ context.Context, stateHash string) (models.OIDCAuthRequest, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	request, ok := r.requests[stateHash]
+	if !ok || time.Now().After(request.ExpiredAt) {
+		return models.OIDCAuthRequest{}, models.ErrNotFound
+	}
+
+	delete(r.requests, stateHash)
+
+	err := r.file.Save(r.requests)
+	if err != nil {
+		r.requests[stateHash] = request
+
+		return models.OIDCAuthRequest{}, errors.Wrap(err, "save oidc auth requests")
+	}
+
+	return request, nil
+}
+
+func NewAuthRequestRepository(c Config) (*AuthRequestRepository, error) {
+	file, err := filestore.New[map[string]models.OIDCAuthRequest](c.AuthRequestsFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "oidc auth request store")
+	}
+
+	requests, err := file.Load()
+	if err != nil {
+		return nil, errors.Wrap(err, "oidc auth request store")
+	}
+
+	if requests == nil {
+		requests = make(map[string]models.OIDCAuthRequest)
+	}
+
+	return &AuthRequestRepository{
+		requests: requests,
+		file:     file,
+	}, nil
+}
+
+type identityKey struct {
+	provider string
+	subject  string
+}
+
+// identity привязка в том виде, в котором она лежит в файле
+type identity struct {
+	Provider string
+	Subject  string
+	UserID   int32
+}
+
+// IdentityRepository привязки учеток провайдеров к пользователям. Привязки хранятся в файле:
+// без него после рестарта аккаунт искался бы заново по email, и учетка со смененным
+// или неподтвержденным адресом попадала бы в другой аккаунт или не входила вовсе.
+type IdentityRepository struct {
+	mu         sync.RWMutex
+	identities map[identityKey]int32
+	file       *filestore.File[[]identity]
+}
+
+func (r *IdentityRepository) UserIDBySubject(_ context.Context, provider, subject string) (int32, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	userID, ok := r.identities[identityKey{provider: provider, subject: subject}]
+	if !ok {
+		return 0, models.ErrNotFound
+	}
+
+	return userID, nil
+}
+
+func (r *IdentityRepository) Link(_ context.Context, provider, subject string, userID int32) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := identityKey{provider: provider, subject: subject}
+
+	previous, ok := r.identities[key]
+	r.identities[key] = userID
+
+	err := r.save()
+	if err != nil {
+		if ok {
+			r.identities[key] = previous
+		} else {
+			delete(r.identities, key)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (r *IdentityRepository) save() error {
+	identities := make([]identity, 0, len(r.identities))
+	for key, userID := range r.identities {
+		identities = append(identities, identity{Provider: key.provider, Subject: key.subject, UserID: userID})
+	}
+
+	slices.SortFunc(identities, func(a, b identity) int {
+		return cmp.Or(cmp.Compare(a.Provider, b.Provider), cmp.Compare(a.Subject, b.Subject))
+	})
+
+	err := r.file.Save(identities)
+	if err != nil {
+		return errors.Wrap(err, "save oidc identities")
+	}
+
+	return nil
+}
+
+func NewIdentityRepository(c Config) (*IdentityRepository, error) {
+	file, err := filestore.New[[]identity](c.IdentitiesFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "oidc identity store")
+	}
+
+	saved, err := file.Load()
+	if err != nil {
+		return nil, errors.Wrap(err, "oidc identity store")
+	}
+
+	identities := make(map[identityKey]int32, len(saved))
+	for _, i := range saved {
+		identities[identityKey{provider: i.Provider, subject: i.Subject}] = i.UserID
+	}
+
+	return &IdentityRepository{
+		identities: identities,
+		file:       file,
+	}, nil
+}
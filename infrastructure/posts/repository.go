@@ -7,11 +7,15 @@ import (
 	"github.com/vorotilkin/twitter-posts/proto"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"sync"
 	"twitter-bff/domain/models"
 	"twitter-bff/infrastructure/posts/hydrators"
 	"twitter-bff/pkg/grpc"
 )
 
+// postsByIDsConcurrency сколько запросов PostByID PostsByIDs держит в twitter-posts одновременно
+const postsByIDsConcurrency = 8
+
 type Repository struct {
 	client *grpc.Client
 }
@@ -32,7 +36,7 @@ func (r *Repository) Create(ctx context.Context, userID int32, body string) (mod
 	return hydrators.DomainPost(response.GetPost()), nil
 }
 
-func (r *Repository) PostsByUserID(ctx context.Context, userID int32) ([]models.Post, error) {
+func (r *Repository) PostsByUserID(ctx context.Context, userID, currentUserID, limit int32) ([]models.Post, error) {
 	if userID == 0 {
 		return nil, errors.Wrap(models.ErrInvalidArgument, "user id = 0")
 	}
@@ -47,7 +51,11 @@ func (r *Repository) PostsByUserID(ctx context.Context, userID int32) ([]models.
 			FilterUsers: &proto.FilterByUserIDs{
 				UserIds: []int32{userID},
 			},
+			Pagination: &proto.FilterByPagination{
+				PerPage: lo.Ternary(limit != 0, limit, 1000),
+			},
 		},
+		CurrentUserId: currentUserID,
 	}
 
 	response, err := client.Posts(ctx, &req)
@@ -104,6 +112,47 @@ func (r *Repository) PostByID(ctx context.Context, postID int32, userID int32) (
 	return hydrators.DomainPost(response.GetPost()), nil
 }
 
+// PostsByIDs загружает посты пачкой, удаленных постов в результате нет.
+// TODO(twitter-posts): в контракте v1.3.1 нет выборки по списку ID, поэтому пачка собирается
+// параллельными PostByID. Заменить одним запросом, когда RPC появится.
+func (r *Repository) PostsByIDs(ctx context.Context, postIDs []int32, userID int32) (map[int32]models.Post, error) {
+	postIDs = lo.Uniq(postIDs)
+
+	posts := make([]models.Post, len(postIDs))
+	errs := make([]error, len(postIDs))
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, postsByIDsConcurrency)
+
+	for i, postID := range postIDs {
+		wg.Add(1)
+		slots <- struct{}{}
+
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			posts[i], errs[i] = r.PostByID(ctx, postID, userID)
+		}()
+	}
+
+	wg.Wait()
+
+	result := make(map[int32]models.Post, len(postIDs))
+	for i, postID := range postIDs {
+		if errors.Is(errs[i], models.ErrNotFound) {
+			continue
+		}
+		if errs[i] != nil {
+			return nil, errors.Wrapf(errs[i], "PostByID %d", postID)
+		}
+
+		result[postID] = posts[i]
+	}
+
+	return result, nil
+}
+
 func (r *Repository) CommentsByPostID(ctx context.Context, postID int32) ([]models.Comment, error) {
 	client := proto.NewPostsClient(r.client.Connection())
 
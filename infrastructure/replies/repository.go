@@ -0,0 +1,111 @@
+package replies
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"slices"
+	"sync"
+	"twitter-bff/pkg/filestore"
+)
+
+type Config struct {
+	// File JSON файл со связями ответов
+	File string
+}
+
+// Repository хранит в файле связи ответов с родительскими постами: в twitter-posts их нет,
+// а без файла после рестарта ответы превращались бы в обычные посты и выпадали из веток.
+type Repository struct {
+	mu      sync.RWMutex
+	parents map[int32]int32
+	// replies ответы на пост в порядке создания, строится по parents
+	replies map[int32][]int32
+	file    *filestore.File[map[int32]int32]
+}
+
+func (r *Repository) SaveReply(_ context.Context, postID, parentID int32) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.parents[postID]; ok {
+		return nil
+	}
+
+	r.parents[postID] = parentID
+
+	err := r.file.Save(r.parents)
+	if err != nil {
+		delete(r.parents, postID)
+
+		return errors.Wrap(err, "save replies")
+	}
+
+	r.replies[parentID] = append(r.replies[parentID], postID)
+
+	return nil
+}
+
+func (r *Repository) Parents(_ context.Context, postIDs []int32) (map[int32]int32, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	parents := make(map[int32]int32, len(postIDs))
+	for _, postID := range postIDs {
+		if parentID, ok := r.parents[postID]; ok {
+			parents[postID] = parentID
+		}
+	}
+
+	return parents, nil
+}
+
+func (r *Repository) Replies(_ context.Context, postID int32) ([]int32, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return slices.Clone(r.replies[postID]), nil
+}
+
+func (r *Repository) ReplyCounts(_ context.Context, postIDs []int32) (map[int32]int32, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	counts := make(map[int32]int32, len(postIDs))
+	for _, postID := range postIDs {
+		counts[postID] = int32(len(r.replies[postID]))
+	}
+
+	return counts, nil
+}
+
+func NewRepository(c Config) (*Repository, error) {
+	file, err := filestore.New[map[int32]int32](c.File)
+	if err != nil {
+		return nil, errors.Wrap(err, "reply store")
+	}
+
+	parents, err := file.Load()
+	if err != nil {
+		return nil, errors.Wrap(err, "reply store")
+	}
+
+	if parents == nil {
+		parents = make(map[int32]int32)
+	}
+
+	replies := make(map[int32][]int32)
+	for postID, parentID := range parents {
+		replies[parentID] = append(replies[parentID], postID)
+	}
+
+	// ID постов в twitter-posts растут вместе со временем создания
+	for _, ids := range replies {
+		slices.Sort(ids)
+	}
+
+	return &Repository{
+		parents: parents,
+		replies: replies,
+		file:    file,
+	}, nil
+}
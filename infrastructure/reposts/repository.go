@@ -0,0 +1,310 @@
+package reposts
+
+import (
+	"cmp"
+	"context"
+	"github.com/pkg/errors"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+	"twitter-bff/domain/models"
+	"twitter-bff/pkg/filestore"
+)
+
+type Config struct {
+	// File JSON файл с репостами и цитатами
+	File string
+}
+
+type repostKey struct {
+	userID int32
+	postID int32
+}
+
+// state содержимое файла. Индексы по пользователям и постам строятся при загрузке.
+type state struct {
+	LastID  int32
+	Reposts []models.Repost
+	Quotes  map[int32]int32
+}
+
+// Repository хранит в файле репосты и связи цитат с цитируемыми постами, о которых twitter-posts
+// не знает: без файла после рестарта репосты пропадали бы из лент, а цитаты превращались в обычные посты.
+type Repository struct {
+	mu      sync.RWMutex
+	lastID  int32
+	reposts map[repostKey]models.Repost
+	// byUser репосты пользователя от старых к новым, в порядке ленты с конца
+	byUser map[int32][]models.Repost
+	byPost map[int32][]models.Repost
+	quotes map[int32]int32
+	file   *filestore.File[state]
+}
+
+// SaveRepost повторный репост того же поста возвращает уже существующий
+func (r *Repository) SaveRepost(_ context.Context, userID, postID int32, createdAt time.Time) (models.Repost, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := repostKey{userID: userID, postID: postID}
+	if repost, ok := r.reposts[key]; ok {
+		return repost, nil
+	}
+
+	repost := models.Repost{
+		ID:        r.lastID + 1,
+		UserID:    userID,
+		PostID:    postID,
+		CreatedAt: createdAt,
+	}
+
+	r.lastID++
+	r.add(repost)
+
+	err := r.save()
+	if err != nil {
+		r.remove(key)
+		r.lastID--
+
+		return models.Repost{}, err
+	}
+
+	return repost, nil
+}
+
+func (r *Repository) DeleteRepost(_ context.Context, userID, postID int32) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := repostKey{userID: userID, postID: postID}
+
+	repost, ok := r.reposts[key]
+	if !ok {
+		return nil
+	}
+
+	r.remove(key)
+
+	err := r.save()
+	if err != nil {
+		r.add(repost)
+
+		return err
+	}
+
+	return nil
+}
+
+// RepostsByUsers последние репосты постов пользователями userIDs после курсора, от новых к старым,
+// не больше limit. Пост попадает в выборку один раз, своим последним репостом, и только если
+// этот репост идет после курсора: более ранние репосты того же поста уже были в ленте выше.
+func (r *Repository) RepostsByUsers(
+	_ context.Context,
+	userIDs []int32,
+	cursor models.PostCursor,
+	limit int32,
+) ([]models.Repost, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := make(map[int32]struct{}, len(userIDs))
+	for _, userID := range userIDs {
+		users[userID] = struct{}{}
+	}
+
+	// в страницу попадут не больше limit репостов каждого пользователя, более старые
+	// вытеснены его же более новыми репостами других постов
+	latest := make(map[int32]models.Repost)
+	for userID := range users {
+		reposts := r.byUser[userID]
+
+		end := sort.Search(len(reposts), func(i int) bool {
+			return !cursor.Precedes(reposts[i].Cursor())
+		})
+
+		for _, repost := range reposts[max(0, end-int(limit)):end] {
+			if _, ok := latest[repost.PostID]; ok {
+				continue
+			}
+
+			last := r.latestRepost(repost.PostID, users)
+			if cursor.Precedes(last.Cursor()) {
+				latest[repost.PostID] = last
+			}
+		}
+	}
+
+	result := make([]models.Repost, 0, len(latest))
+	for _, repost := range latest {
+		result = append(result, repost)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Cursor().Precedes(result[j].Cursor())
+	})
+
+	return result[:min(len(result), int(limit))], nil
+}
+
+func (r *Repository) latestRepost(postID int32, users map[int32]struct{}) models.Repost {
+	var last models.Repost
+	for _, repost := range r.byPost[postID] {
+		if _, ok := users[repost.UserID]; !ok {
+			continue
+		}
+
+		if last.ID == 0 || repost.Cursor().Precedes(last.Cursor()) {
+			last = repost
+		}
+	}
+
+	return last
+}
+
+func (r *Repository) RepostCounts(_ context.Context, postIDs []int32) (map[int32]int32, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	counts := make(map[int32]int32, len(postIDs))
+	for _, postID := range postIDs {
+		if count := len(r.byPost[postID]); count > 0 {
+			counts[postID] = int32(count)
+		}
+	}
+
+	return counts, nil
+}
+
+func (r *Repository) RepostedByUsers(_ context.Context, userIDs, postIDs []int32) (map[int32]bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	reposted := make(map[int32]bool, len(postIDs))
+	for _, postID := range postIDs {
+		for _, userID := range userIDs {
+			if _, ok := r.reposts[repostKey{userID: userID, postID: postID}]; ok {
+				reposted[postID] = true
+				break
+			}
+		}
+	}
+
+	return reposted, nil
+}
+
+func (r *Repository) SaveQuote(_ context.Context, postID, quotedPostID int32) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous, ok := r.quotes[postID]
+	r.quotes[postID] = quotedPostID
+
+	err := r.save()
+	if err != nil {
+		if ok {
+			r.quotes[postID] = previous
+		} else {
+			delete(r.quotes, postID)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (r *Repository) QuotedPosts(_ context.Context, postIDs []int32) (map[int32]int32, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	quoted := make(map[int32]int32, len(postIDs))
+	for _, postID := range postIDs {
+		if quotedPostID, ok := r.quotes[postID]; ok {
+			quoted[postID] = quotedPostID
+		}
+	}
+
+	return quoted, nil
+}
+
+func (r *Repository) add(repost models.Repost) {
+	r.reposts[repostKey{userID: repost.UserID, postID: repost.PostID}] = repost
+
+	// почти всегда это вставка в конец, поиск нужен на случай одинакового или отставшего времени
+	reposts := r.byUser[repost.UserID]
+	i := sort.Search(len(reposts), func(i int) bool {
+		return !repost.Cursor().Precedes(reposts[i].Cursor())
+	})
+	r.byUser[repost.UserID] = slices.Insert(reposts, i, repost)
+
+	r.byPost[repost.PostID] = append(r.byPost[repost.PostID], repost)
+}
+
+func (r *Repository) remove(key repostKey) {
+	delete(r.reposts, key)
+
+	r.byUser[key.userID] = slices.DeleteFunc(r.byUser[key.userID], func(repost models.Repost) bool {
+		return repost.PostID == key.postID
+	})
+	if len(r.byUser[key.userID]) == 0 {
+		delete(r.byUser, key.userID)
+	}
+
+	r.byPost[key.postID] = slices.DeleteFunc(r.byPost[key.postID], func(repost models.Repost) bool {
+		return repost.UserID == key.userID
+	})
+	if len(r.byPost[key.postID]) == 0 {
+		delete(r.byPost, key.postID)
+	}
+}
+
+// save перезаписывает файл. Если записать не удалось, вызывающий откатывает изменение.
+func (r *Repository) save() error {
+	reposts := make([]models.Repost, 0, len(r.reposts))
+	for _, repost := range r.reposts {
+		reposts = append(reposts, repost)
+	}
+
+	slices.SortFunc(reposts, func(a, b models.Repost) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	err := r.file.Save(state{LastID: r.lastID, Reposts: reposts, Quotes: r.quotes})
+	if err != nil {
+		return errors.Wrap(err, "save reposts")
+	}
+
+	return nil
+}
+
+func NewRepository(c Config) (*Repository, error) {
+	file, err := filestore.New[state](c.File)
+	if err != nil {
+		return nil, errors.Wrap(err, "repost store")
+	}
+
+	saved, err := file.Load()
+	if err != nil {
+		return nil, errors.Wrap(err, "repost store")
+	}
+
+	r := &Repository{
+		lastID:  saved.LastID,
+		reposts: make(map[repostKey]models.Repost, len(saved.Reposts)),
+		byUser:  make(map[int32][]models.Repost),
+		byPost:  make(map[int32][]models.Repost),
+		quotes:  saved.Quotes,
+		file:    file,
+	}
+
+	if r.quotes == nil {
+		r.quotes = make(map[int32]int32)
+	}
+
+	for _, repost := range saved.Reposts {
+		r.add(repost)
+	}
+
+	return r, nil
+}
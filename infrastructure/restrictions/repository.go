@@ -0,0 +1,41 @@
+package restrictions
+
+import (
+	"context"
+	"sync"
+)
+
+// Repository запреты на публикацию, выставленные администратором. После рестарта
+// их нужно выставить заново.
+type Repository struct {
+	mu       sync.RWMutex
+	disabled map[int32]struct{}
+}
+
+func (r *Repository) IsPostingDisabled(_ context.Context, userID int32) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.disabled[userID]
+
+	return ok, nil
+}
+
+func (r *Repository) SetPostingDisabled(_ context.Context, userID int32, disabled bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if disabled {
+		r.disabled[userID] = struct{}{}
+	} else {
+		delete(r.disabled, userID)
+	}
+
+	return nil
+}
+
+func NewRepository() *Repository {
+	return &Repository{
+		disabled: make(map[int32]struct{}),
+	}
+}
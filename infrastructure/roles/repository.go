@@ -0,0 +1,37 @@
+package roles
+
+import (
+	"context"
+	"github.com/samber/lo"
+	"twitter-bff/domain/models"
+)
+
+// Config назначение ролей по ID пользователя. В twitter-users ролей нет, поэтому они задаются в конфиге.
+type Config struct {
+	Admins     []int32
+	Moderators []int32
+}
+
+type Repository struct {
+	roles map[int32][]string
+}
+
+func (r *Repository) Roles(_ context.Context, userID int32) ([]string, error) {
+	return r.roles[userID], nil
+}
+
+func NewRepository(c Config) *Repository {
+	roles := make(map[int32][]string)
+
+	for _, userID := range lo.Uniq(c.Admins) {
+		roles[userID] = append(roles[userID], models.RoleAdmin)
+	}
+
+	for _, userID := range lo.Uniq(c.Moderators) {
+		roles[userID] = append(roles[userID], models.RoleModerator)
+	}
+
+	return &Repository{
+		roles: roles,
+	}
+}
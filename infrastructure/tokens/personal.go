@@ -0,0 +1,100 @@
+package tokens
+
+import (
+	"context"
+	"sync"
+	"time"
+	"twitter-bff/domain/models"
+)
+
+// PersonalRepository персональные токены по хэшу значения. Рестарт удаляет все токены,
+// пользователям придется выпустить их заново.
+type PersonalRepository struct {
+	mu     sync.Mutex
+	tokens map[string]models.PersonalToken
+}
+
+func (r *PersonalRepository) Save(_ context.Context, token models.PersonalToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tokens[token.Hash] = token
+
+	return nil
+}
+
+func (r *PersonalRepository) FetchByHash(_ context.Context, hash string) (models.PersonalToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.tokens[hash]
+	if !ok {
+		return models.PersonalToken{}, models.ErrNotFound
+	}
+
+	return token, nil
+}
+
+func (r *PersonalRepository) ListByUser(_ context.Context, userID int32) ([]models.PersonalToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tokens := make([]models.PersonalToken, 0)
+	for _, token := range r.tokens {
+		if token.UserID == userID {
+			tokens = append(tokens, token)
+		}
+	}
+
+	return tokens, nil
+}
+
+func (r *PersonalRepository) Delete(_ context.Context, userID int32, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for hash, token := range r.tokens {
+		if token.ID == id && token.UserID == userID {
+			delete(r.tokens, hash)
+
+			return nil
+		}
+	}
+
+	return models.ErrNotFound
+}
+
+func (r *PersonalRepository) DeleteByUser(_ context.Context, userID int32) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for hash, token := range r.tokens {
+		if token.UserID == userID {
+			delete(r.tokens, hash)
+		}
+	}
+
+	return nil
+}
+
+func (r *PersonalRepository) Touch(_ context.Context, id string, lastUsedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for hash, token := range r.tokens {
+		if token.ID == id {
+			token.LastUsedAt = lastUsedAt
+			r.tokens[hash] = token
+
+			return nil
+		}
+	}
+
+	return models.ErrNotFound
+}
+
+func NewPersonalRepository() *PersonalRepository {
+	return &PersonalRepository{
+		tokens: make(map[string]models.PersonalToken),
+	}
+}
@@ -0,0 +1,112 @@
+package tokens
+
+import (
+	"context"
+	"sync"
+	"time"
+	"twitter-bff/domain/models"
+)
+
+// Repository refresh токены и сессии. Сессия соответствует семейству refresh токенов
+// и удаляется вместе с ним, рестарт завершает все сессии.
+type Repository struct {
+	mu            sync.Mutex
+	refreshTokens map[string]models.RefreshToken
+	sessions      map[string]models.Session
+}
+
+func (r *Repository) Save(_ context.Context, token models.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.evictExpired(time.Now())
+	r.refreshTokens[token.Hash] = token
+
+	return nil
+}
+
+func (r *Repository) FetchByHash(_ context.Context, hash string) (models.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.refreshTokens[hash]
+	if !ok {
+		return models.RefreshToken{}, models.ErrNotFound
+	}
+
+	return token, nil
+}
+
+func (r *Repository) MarkUsed(_ context.Context, hash string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.refreshTokens[hash]
+	if !ok {
+		return false, models.ErrNotFound
+	}
+
+	if token.Used {
+		return false, nil
+	}
+
+	token.Used = true
+	r.refreshTokens[hash] = token
+
+	return true, nil
+}
+
+func (r *Repository) RevokeFamily(_ context.Context, familyID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for hash, token := range r.refreshTokens {
+		if token.FamilyID == familyID {
+			delete(r.refreshTokens, hash)
+		}
+	}
+
+	delete(r.sessions, familyID)
+
+	return nil
+}
+
+func (r *Repository) RevokeUser(_ context.Context, userID int32) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for hash, token := range r.refreshTokens {
+		if token.UserID == userID {
+			delete(r.refreshTokens, hash)
+		}
+	}
+
+	for id, session := range r.sessions {
+		if session.UserID == userID {
+			delete(r.sessions, id)
+		}
+	}
+
+	return nil
+}
+
+func (r *Repository) evictExpired(now time.Time) {
+	for hash, token := range r.refreshTokens {
+		if now.After(token.ExpiredAt) {
+			delete(r.refreshTokens, hash)
+		}
+	}
+
+	for id, session := range r.sessions {
+		if now.After(session.ExpiredAt) {
+			delete(r.sessions, id)
+		}
+	}
+}
+
+func NewRepository() *Repository {
+	return &Repository{
+		refreshTokens: make(map[string]models.RefreshToken),
+		sessions:      make(map[string]models.Session),
+	}
+}
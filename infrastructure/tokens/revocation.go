@@ -0,0 +1,132 @@
+package tokens
+
+import (
+	"context"
+	"sync"
+	"time"
+	"twitter-bff/domain/models"
+)
+
+const defaultEvictInterval = time.Minute
+
+type revokedUser struct {
+	issuedBefore time.Time
+	expiredAt    time.Time
+}
+
+// RevocationRepository отозванные токены. Записи живут, пока не истечет сам токен, после чего
+// их удаляет фоновая очистка. Рестарт теряет записи, и отозванные access токены снова
+// принимаются до своего истечения, то есть не дольше AccessTokenTTL.
+type RevocationRepository struct {
+	mu       sync.RWMutex
+	tokens   map[string]time.Time
+	users    map[int32]revokedUser
+	sessions map[string]time.Time
+	done     chan struct{}
+}
+
+func (r *RevocationRepository) RevokeToken(_ context.Context, tokenID string, expiredAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tokens[tokenID] = expiredAt
+
+	return nil
+}
+
+func (r *RevocationRepository) RevokeUserTokens(_ context.Context, userID int32, issuedBefore, expiredAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.users[userID] = revokedUser{
+		issuedBefore: issuedBefore,
+		expiredAt:    expiredAt,
+	}
+
+	return nil
+}
+
+func (r *RevocationRepository) RevokeSession(_ context.Context, sessionID string, expiredAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.sessions[sessionID] = expiredAt
+
+	return nil
+}
+
+func (r *RevocationRepository) IsRevoked(_ context.Context, jUser models.JWTUser) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.tokens[jUser.ID]; ok && len(jUser.ID) != 0 {
+		return true, nil
+	}
+
+	if _, ok := r.sessions[jUser.SessionID]; ok && len(jUser.SessionID) != 0 {
+		return true, nil
+	}
+
+	user, ok := r.users[jUser.UserID]
+	if ok && jUser.IssuedAt.Before(user.issuedBefore) {
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func (r *RevocationRepository) OnStart(_ context.Context) error {
+	go func() {
+		ticker := time.NewTicker(defaultEvictInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-r.done:
+				return
+			case now := <-ticker.C:
+				r.evictExpired(now)
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (r *RevocationRepository) OnStop(_ context.Context) error {
+	close(r.done)
+
+	return nil
+}
+
+func (r *RevocationRepository) evictExpired(now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, expiredAt := range r.tokens {
+		if now.After(expiredAt) {
+			delete(r.tokens, id)
+		}
+	}
+
+	for id, expiredAt := range r.sessions {
+		if now.After(expiredAt) {
+			delete(r.sessions, id)
+		}
+	}
+
+	for userID, user := range r.users {
+		if now.After(user.expiredAt) {
+			delete(r.users, userID)
+		}
+	}
+}
+
+func NewRevocationRepository() *RevocationRepository {
+	return &RevocationRepository{
+		tokens:   make(map[string]time.Time),
+		users:    make(map[int32]revokedUser),
+		sessions: make(map[string]time.Time),
+		done:     make(chan struct{}),
+	}
+}
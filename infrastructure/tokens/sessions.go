@@ -0,0 +1,67 @@
+package tokens
+
+import (
+	"context"
+	"time"
+	"twitter-bff/domain/models"
+)
+
+func (r *Repository) SaveSession(_ context.Context, session models.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.sessions[session.ID] = session
+
+	return nil
+}
+
+func (r *Repository) TouchSession(
+	_ context.Context,
+	id string,
+	client models.ClientInfo,
+	lastSeenAt, expiredAt time.Time,
+) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, ok := r.sessions[id]
+	if !ok {
+		return models.ErrNotFound
+	}
+
+	session.IP = client.IP
+	session.UserAgent = client.UserAgent
+	session.LastSeenAt = lastSeenAt
+	session.ExpiredAt = expiredAt
+	r.sessions[id] = session
+
+	return nil
+}
+
+func (r *Repository) FetchSession(_ context.Context, id string) (models.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, ok := r.sessions[id]
+	if !ok || time.Now().After(session.ExpiredAt) {
+		return models.Session{}, models.ErrNotFound
+	}
+
+	return session, nil
+}
+
+func (r *Repository) ListSessions(_ context.Context, userID int32) ([]models.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	sessions := make([]models.Session, 0)
+
+	for _, session := range r.sessions {
+		if session.UserID == userID && !now.After(session.ExpiredAt) {
+			sessions = append(sessions, session)
+		}
+	}
+
+	return sessions, nil
+}
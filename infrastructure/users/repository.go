@@ -86,6 +86,9 @@ func (r *Repository) FetchUserByEmail(ctx context.Context, email string) (models
 	req := proto.UserByEmailRequest{Email: email}
 
 	response, err := client.UserByEmail(ctx, &req)
+	if status.Code(err) == codes.NotFound {
+		return models.User{}, models.ErrNotFound
+	}
 	if err != nil {
 		return models.User{}, errors.Wrap(err, "FetchUserByEmail")
 	}
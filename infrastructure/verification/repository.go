@@ -0,0 +1,72 @@
+package verification
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"sync"
+	"twitter-bff/pkg/filestore"
+)
+
+type Config struct {
+	// File JSON файл с подтвержденными адресами, обязателен: без него сервис не стартует
+	File string
+}
+
+// Repository хранит подтвержденные email в файле: в twitter-users нет поля для этого признака,
+// а потеря подтверждений при рестарте с restrictUnverified запретила бы публикации всем пользователям
+type Repository struct {
+	mu       sync.RWMutex
+	verified map[int32]string
+	file     *filestore.File[map[int32]string]
+}
+
+func (r *Repository) MarkVerified(_ context.Context, userID int32, email string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous, ok := r.verified[userID]
+	r.verified[userID] = email
+
+	err := r.file.Save(r.verified)
+	if err != nil {
+		if ok {
+			r.verified[userID] = previous
+		} else {
+			delete(r.verified, userID)
+		}
+
+		return errors.Wrap(err, "save verified emails")
+	}
+
+	return nil
+}
+
+func (r *Repository) IsVerified(_ context.Context, userID int32, email string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	verifiedEmail, ok := r.verified[userID]
+
+	return ok && verifiedEmail == email, nil
+}
+
+func NewRepository(c Config) (*Repository, error) {
+	file, err := filestore.New[map[int32]string](c.File)
+	if err != nil {
+		return nil, errors.Wrap(err, "email verification store")
+	}
+
+	verified, err := file.Load()
+	if err != nil {
+		return nil, errors.Wrap(err, "email verification store")
+	}
+
+	if verified == nil {
+		verified = make(map[int32]string)
+	}
+
+	return &Repository{
+		verified: verified,
+		file:     file,
+	}, nil
+}
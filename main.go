@@ -2,17 +2,36 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"github.com/go-playground/validator/v10"
 	"go.uber.org/fx"
 	"go.uber.org/fx/fxevent"
 	"go.uber.org/zap"
+	"maps"
+	"time"
 	"twitter-bff/api"
 	"twitter-bff/domain/services"
+	"twitter-bff/infrastructure/attempts"
+	"twitter-bff/infrastructure/audit"
+	"twitter-bff/infrastructure/breached"
+	"twitter-bff/infrastructure/hashtags"
+	"twitter-bff/infrastructure/mail"
+	"twitter-bff/infrastructure/mfa"
+	"twitter-bff/infrastructure/oidc"
 	"twitter-bff/infrastructure/posts"
+	"twitter-bff/infrastructure/replies"
+	"twitter-bff/infrastructure/reposts"
+	"twitter-bff/infrastructure/restrictions"
+	"twitter-bff/infrastructure/roles"
+	"twitter-bff/infrastructure/tokens"
 	"twitter-bff/infrastructure/users"
+	"twitter-bff/infrastructure/verification"
+	"twitter-bff/openapigen"
+	"twitter-bff/openapigen/admin"
 	"twitter-bff/pkg/configuration"
 	"twitter-bff/pkg/grpc"
 	"twitter-bff/pkg/http"
+	"twitter-bff/pkg/keys"
 	"twitter-bff/usecases"
 )
 
@@ -31,7 +50,61 @@ type config struct {
 		}
 	}
 	Jwt struct {
-		Secret string
+		AccessTtl  time.Duration
+		RefreshTtl time.Duration
+		Keys       []keys.KeyConfig
+	}
+	Password struct {
+		MinLength      int
+		MinCharClasses int
+		BreachedDir    string
+	}
+	EmailVerification struct {
+		TokenTtl           time.Duration
+		Url                string
+		ResendInterval     time.Duration
+		MaxResends         int
+		ResendWindow       time.Duration
+		RestrictUnverified bool
+		File               string
+	}
+	Mfa struct {
+		Issuer       string
+		ChallengeTtl time.Duration
+		File         string
+	}
+	Oidc struct {
+		FrontendUrl      string
+		AuthRequestTtl   time.Duration
+		Providers        []oidc.ProviderConfig
+		AuthRequestsFile string
+		IdentitiesFile   string
+	}
+	Mail struct {
+		// Driver smtp или outbox
+		Driver string
+		Smtp   mail.SMTPConfig
+		Outbox struct {
+			Dir string
+		}
+	}
+	Posts struct {
+		RepostsFile  string
+		RepliesFile  string
+		HashtagsFile string
+	}
+	Rbac          roles.Config
+	Impersonation struct {
+		TokenTtl time.Duration
+		AuditLog string
+	}
+	Login struct {
+		MaxAccountFailures int
+		MaxIpFailures      int
+		Lockout            time.Duration
+		Window             time.Duration
+		Backoff            time.Duration
+		MaxBackoff         time.Duration
 	}
 }
 
@@ -55,17 +128,137 @@ func main() {
 		fx.Provide(http.NewServer),
 		fx.Provide(newConfig),
 		fx.Provide(validator.New),
-		fx.Provide(func(c *config) http.Config {
-			return http.Config{
-				Addr:      c.Http.Server.Addr,
-				SecretKey: c.Jwt.Secret,
+		fx.Provide(func(c *config) (http.Config, error) {
+			err := c.Http.Server.Cookie.Validate()
+			if err != nil {
+				return http.Config{}, err
 			}
+
+			return http.Config{
+				Addr:       c.Http.Server.Addr,
+				TrustProxy: c.Http.Server.TrustProxy,
+				Cookie:     c.Http.Server.Cookie,
+			}, nil
 		}),
 		fx.Provide(func(c *config) services.Config {
 			return services.Config{
-				SecretKey: c.Jwt.Secret,
+				AccessTokenTTL:  c.Jwt.AccessTtl,
+				RefreshTokenTTL: c.Jwt.RefreshTtl,
+				Throttle: services.LoginThrottleConfig{
+					MaxAccountFailures: c.Login.MaxAccountFailures,
+					MaxIPFailures:      c.Login.MaxIpFailures,
+					LockoutDuration:    c.Login.Lockout,
+					FailuresWindow:     c.Login.Window,
+					BackoffBase:        c.Login.Backoff,
+					BackoffMax:         c.Login.MaxBackoff,
+				},
+				EmailVerification: services.EmailVerificationConfig{
+					TokenTTL:           c.EmailVerification.TokenTtl,
+					URL:                c.EmailVerification.Url,
+					ResendInterval:     c.EmailVerification.ResendInterval,
+					MaxResends:         c.EmailVerification.MaxResends,
+					ResendWindow:       c.EmailVerification.ResendWindow,
+					RestrictUnverified: c.EmailVerification.RestrictUnverified,
+				},
+				MFA: services.MFAConfig{
+					Issuer:       c.Mfa.Issuer,
+					ChallengeTTL: c.Mfa.ChallengeTtl,
+				},
+				OIDC: services.OIDCConfig{
+					AuthRequestTTL: c.Oidc.AuthRequestTtl,
+					FrontendURL:    c.Oidc.FrontendUrl,
+				},
+				Password: services.PasswordPolicyConfig{
+					MinLength:      c.Password.MinLength,
+					MinCharClasses: c.Password.MinCharClasses,
+				},
+				Impersonation: services.ImpersonationConfig{
+					TokenTTL: c.Impersonation.TokenTtl,
+				},
 			}
 		}),
+		fx.Provide(func() (http.Operations, error) {
+			swagger, err := openapigen.GetSwagger()
+			if err != nil {
+				return nil, err
+			}
+
+			adminSwagger, err := admin.GetSwagger()
+			if err != nil {
+				return nil, err
+			}
+
+			operations := http.NewOperations(swagger, api.BaseURL)
+			maps.Copy(operations, http.NewOperations(adminSwagger, api.AdminBaseURL))
+
+			return operations, nil
+		}),
+		fx.Provide(func(c *config) breached.Config {
+			return breached.Config{
+				Dir: c.Password.BreachedDir,
+			}
+		}),
+		fx.Provide(func(c *config) (services.Mailer, error) {
+			switch c.Mail.Driver {
+			case "smtp":
+				return mail.NewSMTPMailer(c.Mail.Smtp)
+			case "outbox", "":
+				return mail.NewOutboxMailer(c.Mail.Outbox.Dir)
+			default:
+				return nil, fmt.Errorf("unknown mail driver %q", c.Mail.Driver)
+			}
+		}),
+		fx.Provide(func(c *config) oidc.Config {
+			return oidc.Config{
+				Providers:        c.Oidc.Providers,
+				AuthRequestsFile: c.Oidc.AuthRequestsFile,
+				IdentitiesFile:   c.Oidc.IdentitiesFile,
+			}
+		}),
+		fx.Provide(func(c *config) roles.Config {
+			return c.Rbac
+		}),
+		fx.Provide(func(c *config) mfa.Config {
+			return mfa.Config{
+				File: c.Mfa.File,
+			}
+		}),
+		fx.Provide(func(c *config) verification.Config {
+			return verification.Config{
+				File: c.EmailVerification.File,
+			}
+		}),
+		fx.Provide(func(c *config) hashtags.Config {
+			return hashtags.Config{
+				File: c.Posts.HashtagsFile,
+			}
+		}),
+		fx.Provide(func(c *config) replies.Config {
+			return replies.Config{
+				File: c.Posts.RepliesFile,
+			}
+		}),
+		fx.Provide(func(c *config) reposts.Config {
+			return reposts.Config{
+				File: c.Posts.RepostsFile,
+			}
+		}),
+		fx.Provide(func(c *config) audit.Config {
+			return audit.Config{
+				File: c.Impersonation.AuditLog,
+			}
+		}),
+		fx.Provide(func(c *config) keys.Config {
+			return keys.Config{
+				Keys: c.Jwt.Keys,
+			}
+		}),
+		fx.Provide(fx.Annotate(
+			keys.NewKeySet,
+			fx.As(fx.Self()),
+			fx.As(new(services.TokenSigner)),
+			fx.As(new(services.TokenParser)),
+		)),
 		fx.Provide(fx.Annotate(func(c *config) grpc.Config { return grpc.Config{Address: c.Grpc.Client.Users.Address} },
 			fx.ResultTags(`name:"usersConfig"`))),
 		fx.Provide(fx.Annotate(func(c *config) grpc.Config {
@@ -83,6 +276,10 @@ func main() {
 			fx.As(new(services.UpdateUserByIDRepository)),
 			fx.As(new(services.PostsUsersByIDsRepository)),
 			fx.As(new(services.FollowRepository)),
+			fx.As(new(services.EmailVerificationUsersRepository)),
+			fx.As(new(services.MFAUsersRepository)),
+			fx.As(new(services.OIDCUsersRepository)),
+			fx.As(new(services.AdminUsersRepository)),
 		)),
 		fx.Provide(fx.Annotate(
 			posts.NewRepository,
@@ -90,20 +287,134 @@ func main() {
 			fx.As(new(services.PostsRepository)),
 			fx.As(new(services.LikeRepository)),
 		)),
+		fx.Provide(fx.Annotate(
+			tokens.NewRepository,
+			fx.As(new(services.RefreshTokenRepository)),
+			fx.As(new(services.LogoutRefreshTokenRepository)),
+			fx.As(new(services.SessionRepository)),
+		)),
+		fx.Provide(fx.Annotate(
+			attempts.NewRepository,
+			fx.As(new(services.LoginAttemptsRepository)),
+		)),
+		fx.Provide(fx.Annotate(
+			attempts.NewRepository,
+			fx.As(new(services.ResendAttemptsRepository)),
+		)),
+		fx.Provide(fx.Annotate(
+			mfa.NewRepository,
+			fx.As(new(services.TOTPRepository)),
+			fx.As(new(services.MFAStatusRepository)),
+		)),
+		fx.Provide(fx.Annotate(
+			verification.NewRepository,
+			fx.As(new(services.EmailVerificationRepository)),
+		)),
+		fx.Provide(fx.Annotate(
+			oidc.NewClient,
+			fx.As(new(services.OIDCClient)),
+		)),
+		fx.Provide(fx.Annotate(
+			oidc.NewAuthRequestRepository,
+			fx.As(new(services.OIDCAuthRequestRepository)),
+		)),
+		fx.Provide(fx.Annotate(
+			oidc.NewIdentityRepository,
+			fx.As(new(services.OIDCIdentityRepository)),
+		)),
+		fx.Provide(fx.Annotate(
+			tokens.NewPersonalRepository,
+			fx.As(new(services.PersonalTokenRepository)),
+		)),
+		fx.Provide(fx.Annotate(
+			roles.NewRepository,
+			fx.As(new(services.RoleRepository)),
+		)),
+		fx.Provide(fx.Annotate(
+			restrictions.NewRepository,
+			fx.As(new(services.PostingRestrictionRepository)),
+		)),
+		fx.Provide(fx.Annotate(
+			audit.NewLogger,
+			fx.As(fx.Self()),
+			fx.As(new(services.AuditLogger)),
+			fx.As(new(http.AuditLogger)),
+		)),
+		fx.Provide(fx.Annotate(
+			replies.NewRepository,
+			fx.As(new(services.ReplyRepository)),
+		)),
+		fx.Provide(fx.Annotate(
+			reposts.NewRepository,
+			fx.As(new(services.RepostRepository)),
+		)),
+		fx.Provide(fx.Annotate(
+			hashtags.NewRepository,
+			fx.As(new(services.HashtagRepository)),
+		)),
+		fx.Provide(fx.Annotate(
+			breached.NewRepository,
+			fx.As(new(services.BreachedPasswordChecker)),
+		)),
+		fx.Provide(fx.Annotate(
+			tokens.NewRevocationRepository,
+			fx.As(fx.Self()),
+			fx.As(new(services.RevocationRepository)),
+			fx.As(new(http.RevocationChecker)),
+		)),
+		fx.Provide(services.NewPasswordPolicy),
 		fx.Provide(services.NewCreateUserService),
-		fx.Provide(services.NewLoginService),
+		fx.Provide(fx.Annotate(
+			services.NewLoginService,
+			fx.As(fx.Self()),
+			fx.As(new(services.SessionIssuer)),
+			fx.As(new(services.SessionStarter)),
+		)),
+		fx.Provide(fx.Annotate(
+			services.NewLogoutService,
+			fx.As(fx.Self()),
+			fx.As(new(services.SessionTerminator)),
+		)),
+		fx.Provide(services.NewMFAService),
+		fx.Provide(services.NewOIDCService),
+		fx.Provide(services.NewSessionsService),
+		fx.Provide(fx.Annotate(
+			services.NewPersonalTokensService,
+			fx.As(fx.Self()),
+			fx.As(new(http.PersonalTokenAuthenticator)),
+		)),
+		fx.Provide(fx.Annotate(
+			services.NewEmailVerificationService,
+			fx.As(fx.Self()),
+			fx.As(new(services.VerificationSender)),
+			fx.As(new(services.EmailVerificationChecker)),
+		)),
 		fx.Provide(services.NewUserByIDService),
 		fx.Provide(services.NewUpdateUserByIDService),
 		fx.Provide(services.NewPostsService),
 		fx.Provide(services.NewFollowService),
 		fx.Provide(services.NewLikeService),
+		fx.Provide(services.NewAdminService),
+		fx.Provide(services.NewImpersonationService),
 		fx.Provide(usecases.NewEchoServer),
+		fx.Provide(usecases.NewAdminServer),
 		fx.Invoke(func(lc fx.Lifecycle, server *http.Server) {
 			lc.Append(fx.Hook{
 				OnStart: server.OnStart,
 				OnStop:  server.OnStop,
 			})
 		}),
+		fx.Invoke(func(lc fx.Lifecycle, repo *tokens.RevocationRepository) {
+			lc.Append(fx.Hook{
+				OnStart: repo.OnStart,
+				OnStop:  repo.OnStop,
+			})
+		}),
+		fx.Invoke(func(lc fx.Lifecycle, logger *audit.Logger) {
+			lc.Append(fx.Hook{
+				OnStop: logger.OnStop,
+			})
+		}),
 		fx.Invoke(fx.Annotate(func(lc fx.Lifecycle, client *grpc.Client) {
 			lc.Append(fx.Hook{
 				OnStart: client.OnStart,
@@ -0,0 +1,361 @@
+// Package admin provides primitives to interact with the openapi HTTP API.
+//
+// Code generated by github.com/oapi-codegen/oapi-codegen/v2 version v2.4.1 DO NOT EDIT.
+package admin
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/labstack/echo/v4"
+	"github.com/oapi-codegen/runtime"
+)
+
+const (
+	BearerAuthScopes = "bearerAuth.Scopes"
+	CookieAuthScopes = "cookieAuth.Scopes"
+)
+
+// Account defines model for Account.
+type Account struct {
+	EmailVerified   bool      `json:"emailVerified"`
+	MfaEnabled      bool      `json:"mfaEnabled"`
+	PostingDisabled bool      `json:"postingDisabled"`
+	Roles           []string  `json:"roles"`
+	Sessions        []Session `json:"sessions"`
+	User            User      `json:"user"`
+}
+
+// ImpersonateRequest defines model for ImpersonateRequest.
+type ImpersonateRequest struct {
+	// Reason Причина, например номер обращения в поддержку. Попадает в журнал аудита
+	Reason string `json:"reason"`
+}
+
+// ImpersonationToken defines model for ImpersonationToken.
+type ImpersonationToken struct {
+	AccessToken          string    `json:"accessToken"`
+	AccessTokenExpiresAt time.Time `json:"accessTokenExpiresAt"`
+	UserId               int32     `json:"userId"`
+}
+
+// PostingRequest defines model for PostingRequest.
+type PostingRequest struct {
+	Disabled bool `json:"disabled"`
+}
+
+// Session defines model for Session.
+type Session struct {
+	CreatedAt  time.Time `json:"createdAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+	Id         string    `json:"id"`
+	Ip         string    `json:"ip"`
+	LastSeenAt time.Time `json:"lastSeenAt"`
+	UserAgent  string    `json:"userAgent"`
+}
+
+// User defines model for User.
+type User struct {
+	Email    string `json:"email"`
+	Id       int32  `json:"id"`
+	Name     string `json:"name"`
+	Username string `json:"username"`
+}
+
+// UserList defines model for UserList.
+type UserList struct {
+	Users []User `json:"users"`
+}
+
+// UserID defines model for UserID.
+type UserID = int32
+
+// ListUsersParams defines parameters for ListUsers.
+type ListUsersParams struct {
+	Limit *int32 `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// ImpersonateJSONRequestBody defines body for Impersonate for application/json ContentType.
+type ImpersonateJSONRequestBody = ImpersonateRequest
+
+// SetPostingJSONRequestBody defines body for SetPosting for application/json ContentType.
+type SetPostingJSONRequestBody = PostingRequest
+
+// ServerInterface represents all server handlers.
+type ServerInterface interface {
+	// Последние зарегистрированные пользователи
+	// (GET /users)
+	ListUsers(ctx echo.Context, params ListUsersParams) error
+	// Аккаунт пользователя с состоянием, которое хранит BFF
+	// (GET /users/{id})
+	GetAccount(ctx echo.Context, id UserID) error
+	// Войти от имени пользователя
+	// (POST /users/{id}/impersonate)
+	Impersonate(ctx echo.Context, id UserID) error
+	// Завершить все сессии пользователя
+	// (POST /users/{id}/logout)
+	ForceLogout(ctx echo.Context, id UserID) error
+	// Запретить или разрешить пользователю публиковать посты
+	// (PUT /users/{id}/posting)
+	SetPosting(ctx echo.Context, id UserID) error
+}
+
+// ServerInterfaceWrapper converts echo contexts to parameters.
+type ServerInterfaceWrapper struct {
+	Handler ServerInterface
+}
+
+// ListUsers converts echo context to params.
+func (w *ServerInterfaceWrapper) ListUsers(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(CookieAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListUsersParams
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", ctx.QueryParams(), &params.Limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter limit: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.ListUsers(ctx, params)
+	return err
+}
+
+// GetAccount converts echo context to params.
+func (w *ServerInterfaceWrapper) GetAccount(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id UserID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(CookieAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetAccount(ctx, id)
+	return err
+}
+
+// Impersonate converts echo context to params.
+func (w *ServerInterfaceWrapper) Impersonate(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id UserID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(CookieAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.Impersonate(ctx, id)
+	return err
+}
+
+// ForceLogout converts echo context to params.
+func (w *ServerInterfaceWrapper) ForceLogout(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id UserID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(CookieAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.ForceLogout(ctx, id)
+	return err
+}
+
+// SetPosting converts echo context to params.
+func (w *ServerInterfaceWrapper) SetPosting(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id UserID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(CookieAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.SetPosting(ctx, id)
+	return err
+}
+
+// This is a simple interface which specifies echo.Route addition functions which
+// are present on both echo.Echo and echo.Group, since we want to allow using
+// either of them for path registration
+type EchoRouter interface {
+	CONNECT(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	DELETE(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	GET(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	HEAD(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	OPTIONS(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	PATCH(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	POST(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	PUT(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	TRACE(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+}
+
+// RegisterHandlers adds each server route to the EchoRouter.
+func RegisterHandlers(router EchoRouter, si ServerInterface) {
+	RegisterHandlersWithBaseURL(router, si, "")
+}
+
+// Registers handlers, and prepends BaseURL to the paths, so that the paths
+// can be served under a prefix.
+func RegisterHandlersWithBaseURL(router EchoRouter, si ServerInterface, baseURL string) {
+
+	wrapper := ServerInterfaceWrapper{
+		Handler: si,
+	}
+
+	router.GET(baseURL+"/users", wrapper.ListUsers)
+	router.GET(baseURL+"/users/:id", wrapper.GetAccount)
+	router.POST(baseURL+"/users/:id/impersonate", wrapper.Impersonate)
+	router.POST(baseURL+"/users/:id/logout", wrapper.ForceLogout)
+	router.PUT(baseURL+"/users/:id/posting", wrapper.SetPosting)
+
+}
+
+// Base64 encoded, gzipped, json marshaled Swagger object
+var swaggerSpec = []string{
+
+	"H4sIAAAAAAAC/8xY3W4bxxV+lcG0Fy2wEumfAgHv5Dpq1aZoENltAVsX4+VIGof749mhY1UgIFFN00CG",
+	"FeQmQIs2SJ+AZkiJpEzqFc68UXHO7JLL5ZK2UAnIjcSd3++c851vzswh96MgjkIZmoTXDnkstAikkZq+",
+	"HidSbz3EXyrkNR4Ls889HopA8hpXde5xLV80lZZ1XjO6KT2e+PsyEDhjN9KBMDguNPfuco+bg1i6T7kn",
+	"NW+1Wtlw2mvD96NmaAiEjmKpjZLUIQOhGn+SWu0q3OcwW+hZFDWkCHnL48Gu+DgUzxrL+uMoMSrce6iS",
+	"FYN01HAbKiODJDcmMVqFezgkbRBaiwP8TmSSqCicn/VzLXd5jf+sMvNsJbWzsu0mlK3VTKR+33SMB2+1",
+	"8m5/4iZm8L2Cu+Z8s+iInAk7U0jRs+fSN4hpK4ilTqJQGPmZfNGUSUl4tBRJFOKvukx8rWKDBtY4fG+P",
+	"YGC/ggGMoeMx/AtX2AbvoG+PsGGS/ZzAW3sEHfs19GEMA3vGoMvgCibQgx4OgXMY2ZN1Bt/DBK6gAz3o",
+	"QN+2ady5PbFHtP4lg449gR4MbBs63CuGsOC6FPtq01UUPoo+l+Gi6cL3ZZJMOxfokuv/+FWstEw2zFxq",
+	"1IWRa0YFchGpI8RW/cNSad6sPK4lKKbLl9n+qWPJ0pDXV+RRAcp0aNk+WTYsbOBrKYysX8db8voOVvXS",
+	"qKm4tLkhErMtZXjdEG7sSadqq6lIajobTzC8nCPmAOTNLXPs41RLSoS03OQPo1km/Yflli7pLDOUhuZm",
+	"pbK11JhPVBkPcfqHa68Tz6LwlohpmRaS2PtNrczBNq7nADyTQku90TT7s6/NzIu/+/Mj7hVEcYNykRlM",
+	"Rraro4CZfcka0Z4KmZZJHIUJOoMgU1rRkrNQ7BsTow1+FH2uZLbxij1USDv81pj4j2HjgLmJ6+wPTSMw",
+	"x5l2SZ4w0UgiFkpZpwl/Wfv19meba6QabF+KOqGgKsAtMasD0GVrz78wM5QiVr+XB+6EV+FutIgRvoEe",
+	"vKOjYWCPbZvEvw0D6MLYnkKfkcr3qfnvMIDBOoN/QgfOUfftWaHbnjFaog9v7Yk7FfCg6UAXOgwGcMFe",
+	"rcVSB4rkxmMwgoltw8QewQT3wjXf2Daj70sYuJPn0r6GC5jgIrYNfbi0Z+toozINNPLRF8oYqdlGPVAh",
+	"2/h0i3v8pdRO0Pid9ep6FSMVxTIUseI1fo+aPKqiiD2VKX/3JLEbuU3nDco+R84/phHeXFn2JK3GXjSl",
+	"PpiFoaECZfjKCiwQr1TQDHjtV9WqxwMVuq87JQfKjsczPhLCu9Uq/vOj0KRyJuK4oXyCW3meVgCzvd+X",
+	"iJTQRJACMX6AK6QETGC0JAzQhyF69n71ziKxHoeiafYjrf4q66yZZvz96r3FkfDvIlMoHDUtRd3lezMI",
+	"hD6gUgYm9ph27iFjkTMX0CHG/Tgl8IDo04UOjDMSl+MfcI/nCZnmUG5rx4zKoaq3ltLjN9JkJfMCP8q8",
+	"PxtSScv6Ww1yhq0sxt/ACEZUqI1t+9ZDiZPul9WnpTn+GuvSPqNqcoi1J4yLbJiDv1QrmD1myGNkB0zs",
+	"mSMOvFuQH/slwUYlbLMHm5vXYUdFzSp0Oh+jxJSY+q09nRXMI7ezbeMvOEfRtSf2axjAkLlakSE6GKHt",
+	"K83zG0IFTPjGozI8b9Y7Zk/QSZgnuEqnVO8n9mj9aQj/ze/Wp7RK0dpj0vYUwggmlHjwIzYQnhHttZES",
+	"hYhaYw/o1PTSSF4RoB6lb3e2bNad+SUV/z4Mn4YwSDvdfWVMd5YZoOLpg5ahP16tJTJMlFEvJfuFPcbL",
+	"DfIIreq45Wnb7tRLQ2b/Bh0YuQYv53d76iF7+vbYHuP590v0UxpG+xV0ckPpjGPk7HPo2VMYOieR3URC",
+	"5ixImykCpxTLN5lBKy5R609D7hW0J3cv/P/Eh2qPB1H94MZ0p+TK2mq1ig8VrQXlu3MLCKY3xzIRzJG+",
+	"m6bn+Ba1MCcUWBJRlTPPEhK7S0pXe5znF2YYZQCRns7mcVaX3Yi8evz+3bvlpszJCHTSjMxeFTJL8oNI",
+	"dOEtyW4X+ulhPGRbD4s6/i1mIFadDIXLmYjjl9d/y6Q5r8JFhW5Ee1HTrBDn/9g2XLiEdEKUEwHkBqrA",
+	"l3kxGDIHMRcMAuuKjvnpyw2Zz+jNSPvyEwf15sqJMl78MBM1x8AuGfIPh/gWMyANRIEE3+UQDGzbvk5d",
+	"Pqe+1yZEbq88F9LnNyJDs6Sm25YmfXv5qclq4UnogyS1LPrfpZLTdwl3kaZc/5a0L3V4TUt8jfDNjcjV",
+	"IoFSizICpaKEIC6oZ0qtchq9Qcgn8BanwSjrSsdTuXS6SLRF0/KPFESZ/CvBk52WN/9g8WQHyZJI/TKj",
+	"WMEl/yIhmWnL0OXEkcsXrJwuXXWGedKeu/8MKDWaupG+WdQqlUbki8Z+lJjaR9WPqhURq4rAqzNv7bT+",
+	"NwD6DKirhhgAAA==",
+}
+
+// GetSwagger returns the content of the embedded swagger specification file
+// or error if failed to decode
+func decodeSpec() ([]byte, error) {
+	zipped, err := base64.StdEncoding.DecodeString(strings.Join(swaggerSpec, ""))
+	if err != nil {
+		return nil, fmt.Errorf("error base64 decoding spec: %w", err)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(zipped))
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing spec: %w", err)
+	}
+	var buf bytes.Buffer
+	_, err = buf.ReadFrom(zr)
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing spec: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+var rawSpec = decodeSpecCached()
+
+// a naive cached of a decoded swagger spec
+func decodeSpecCached() func() ([]byte, error) {
+	data, err := decodeSpec()
+	return func() ([]byte, error) {
+		return data, err
+	}
+}
+
+// Constructs a synthetic filesystem for resolving external references when loading openapi specifications.
+func PathToRawSpec(pathToFile string) map[string]func() ([]byte, error) {
+	res := make(map[string]func() ([]byte, error))
+	if len(pathToFile) > 0 {
+		res[pathToFile] = rawSpec
+	}
+
+	return res
+}
+
+// GetSwagger returns the Swagger specification corresponding to the generated code
+// in this file. The external references of Swagger specification are resolved.
+// The logic of resolving external references is tightly connected to "import-mapping" feature.
+// Externally referenced files must be embedded in the corresponding golang packages.
+// Urls can be supported but this task was out of the scope.
+func GetSwagger() (swagger *openapi3.T, err error) {
+	resolvePath := PathToRawSpec("")
+
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = true
+	loader.ReadFromURIFunc = func(loader *openapi3.Loader, url *url.URL) ([]byte, error) {
+		pathToFile := url.String()
+		pathToFile = path.Clean(pathToFile)
+		getSpec, ok := resolvePath[pathToFile]
+		if !ok {
+			err1 := fmt.Errorf("path not found: %s", pathToFile)
+			return nil, err1
+		}
+		return getSpec()
+	}
+	var specData []byte
+	specData, err = rawSpec()
+	if err != nil {
+		return
+	}
+	swagger, err = loader.LoadFromData(specData)
+	if err != nil {
+		return
+	}
+	return
+}
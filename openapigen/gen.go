@@ -4,14 +4,27 @@
 package openapigen
 
 import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
 	"fmt"
 	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
 
+	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/labstack/echo/v4"
 	"github.com/oapi-codegen/runtime"
 	openapi_types "github.com/oapi-codegen/runtime/types"
 )
 
+const (
+	BearerAuthScopes = "bearerAuth.Scopes"
+	CookieAuthScopes = "cookieAuth.Scopes"
+)
+
 // Comment defines model for Comment.
 type Comment struct {
 	Body      string             `json:"body"`
@@ -23,23 +36,235 @@ type Comment struct {
 	UserId    string             `json:"userId"`
 }
 
+// Error defines model for Error.
+type Error struct {
+	Message string `json:"message"`
+}
+
+// FeedItem defines model for FeedItem.
+type FeedItem struct {
+	// Kind post - публикация автора, repost - репост
+	Kind       string     `json:"kind"`
+	Post       Post       `json:"post"`
+	RepostedAt *time.Time `json:"repostedAt,omitempty"`
+	RepostedBy *User      `json:"repostedBy,omitempty"`
+}
+
+// FeedPage defines model for FeedPage.
+type FeedPage struct {
+	Items []FeedItem `json:"items"`
+
+	// NextCursor Курсор следующей страницы, отсутствует на последней
+	NextCursor *string `json:"nextCursor,omitempty"`
+
+	// Truncated true на последней странице, если она уперлась в границу 1000 последних постов, которые
+	// просматривает BFF: более старые посты, если они есть, недоступны. Отсутствует, если посты закончились.
+	Truncated *bool `json:"truncated,omitempty"`
+}
+
+// FieldError defines model for FieldError.
+type FieldError struct {
+	// Code Машиночитаемый код нарушенного правила, например too_short, too_long, too_simple, contains_username, contains_email, breached
+	Code    string `json:"code"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Hashtag defines model for Hashtag.
+type Hashtag struct {
+	PostCount int32 `json:"postCount"`
+
+	// Tag Тег без '#' в нижнем регистре
+	Tag string `json:"tag"`
+}
+
 // JWTResponse defines model for JWTResponse.
 type JWTResponse struct {
 	// AccessToken JWT access token
 	AccessToken string `json:"accessToken"`
+
+	// AccessTokenExpiresAt Access token expiration time
+	AccessTokenExpiresAt time.Time `json:"accessTokenExpiresAt"`
+
+	// CsrfToken Value for the X-CSRF-Token header, bound to this access token. Also set in the csrf-token cookie
+	CsrfToken string `json:"csrfToken"`
+
+	// RefreshToken Opaque refresh token, single use
+	RefreshToken string `json:"refreshToken"`
+
+	// RefreshTokenExpiresAt Refresh token expiration time
+	RefreshTokenExpiresAt time.Time `json:"refreshTokenExpiresAt"`
+}
+
+// LoginError defines model for LoginError.
+type LoginError struct {
+	// Code invalid_request, invalid_credentials, account_locked или mfa_required
+	Code    string        `json:"code"`
+	Message string        `json:"message"`
+	Mfa     *MFAChallenge `json:"mfa,omitempty"`
+
+	// RetryAfter Для account_locked - через сколько секунд можно повторить вход
+	RetryAfter *int `json:"retryAfter,omitempty"`
+}
+
+// LoginRequest defines model for LoginRequest.
+type LoginRequest struct {
+	Email    openapi_types.Email `json:"email"`
+	Password string              `json:"password"`
+}
+
+// LoginSession defines model for LoginSession.
+type LoginSession struct {
+	AccessToken          string    `json:"accessToken"`
+	AccessTokenExpiresAt time.Time `json:"accessTokenExpiresAt"`
+
+	// CsrfToken Value for the X-CSRF-Token header
+	CsrfToken             string    `json:"csrfToken"`
+	RefreshToken          string    `json:"refreshToken"`
+	RefreshTokenExpiresAt time.Time `json:"refreshTokenExpiresAt"`
+
+	// SessionId ID сессии из /v1/sessions
+	SessionId string `json:"sessionId"`
+	User      User   `json:"user"`
+}
+
+// MFAChallenge defines model for MFAChallenge.
+type MFAChallenge struct {
+	Methods []string `json:"methods"`
+
+	// MfaToken Промежуточный токен, годен только для /v1/login/mfa
+	MfaToken          string    `json:"mfaToken"`
+	MfaTokenExpiresAt time.Time `json:"mfaTokenExpiresAt"`
+}
+
+// MFACodeRequest defines model for MFACodeRequest.
+type MFACodeRequest struct {
+	Code string `json:"code"`
+}
+
+// MFALoginRequest defines model for MFALoginRequest.
+type MFALoginRequest struct {
+	// Code 6 цифр из приложения или код восстановления вида ABCDE-FGHIJ
+	Code     string `json:"code"`
+	MfaToken string `json:"mfaToken"`
+}
+
+// PersonalToken defines model for PersonalToken.
+type PersonalToken struct {
+	CreatedAt  time.Time  `json:"createdAt"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	Id         string     `json:"id"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+}
+
+// PersonalTokenCreateRequest defines model for PersonalTokenCreateRequest.
+type PersonalTokenCreateRequest struct {
+	// ExpiresAt Без срока токен действует до отзыва
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+
+	// Name Для чего токен, чтобы отличать его в списке
+	Name string `json:"name"`
+
+	// Scopes Права токена: read, posts:write, follows:write, likes:write
+	Scopes []string `json:"scopes"`
+}
+
+// PersonalTokenCreated defines model for PersonalTokenCreated.
+type PersonalTokenCreated struct {
+	CreatedAt  time.Time  `json:"createdAt"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	Id         string     `json:"id"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+
+	// Token Значение для заголовка Authorization Bearer, больше не показывается
+	Token string `json:"token"`
+}
+
+// PersonalTokenList defines model for PersonalTokenList.
+type PersonalTokenList struct {
+	Tokens []PersonalToken `json:"tokens"`
 }
 
 // Post defines model for Post.
 type Post struct {
-	Body              string             `json:"body"`
-	Comments          []Comment          `json:"comments"`
-	CreatedAt         openapi_types.Date `json:"createdAt"`
-	Id                int32              `json:"id"`
-	IsCurrentUserLike *bool              `json:"isCurrentUserLike,omitempty"`
-	LikeCount         int32              `json:"likeCount"`
-	UpdatedAt         openapi_types.Date `json:"updatedAt"`
-	User              *User              `json:"user,omitempty"`
-	UserId            string             `json:"userId"`
+	Body      string             `json:"body"`
+	Comments  []Comment          `json:"comments"`
+	CreatedAt openapi_types.Date `json:"createdAt"`
+	Id        int32              `json:"id"`
+
+	// InReplyToPostId Пост, на который это ответ
+	InReplyToPostId       *int32 `json:"inReplyToPostId,omitempty"`
+	IsCurrentUserLike     *bool  `json:"isCurrentUserLike,omitempty"`
+	IsCurrentUserReposted *bool  `json:"isCurrentUserReposted,omitempty"`
+	LikeCount             int32  `json:"likeCount"`
+	QuotedPost            *Post  `json:"quotedPost,omitempty"`
+
+	// QuotedPostId Цитируемый пост
+	QuotedPostId *int32             `json:"quotedPostId,omitempty"`
+	ReplyCount   int32              `json:"replyCount"`
+	RepostCount  int32              `json:"repostCount"`
+	UpdatedAt    openapi_types.Date `json:"updatedAt"`
+	User         *User              `json:"user,omitempty"`
+	UserId       string             `json:"userId"`
+}
+
+// RecoveryCodes defines model for RecoveryCodes.
+type RecoveryCodes struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// Session defines model for Session.
+type Session struct {
+	CreatedAt time.Time `json:"createdAt"`
+
+	// Current Сессия, которой принадлежит токен запроса
+	Current bool `json:"current"`
+
+	// ExpiresAt Когда сессия завершится без обновления токенов
+	ExpiresAt time.Time `json:"expiresAt"`
+	Id        string    `json:"id"`
+
+	// Ip Адрес, с которого сессия использовалась последний раз
+	Ip         string    `json:"ip"`
+	LastSeenAt time.Time `json:"lastSeenAt"`
+	UserAgent  string    `json:"userAgent"`
+}
+
+// SessionList defines model for SessionList.
+type SessionList struct {
+	Sessions []Session `json:"sessions"`
+}
+
+// TOTPEnrollment defines model for TOTPEnrollment.
+type TOTPEnrollment struct {
+	OtpauthUri string `json:"otpauthUri"`
+
+	// QrCode PNG с QR кодом otpauthUri в base64
+	QrCode []byte `json:"qrCode"`
+
+	// Secret Секрет в base32 для ручного ввода
+	Secret string `json:"secret"`
+}
+
+// Thread defines model for Thread.
+type Thread struct {
+	// Ancestors Предки поста, первым идет корень ветки
+	Ancestors []Post `json:"ancestors"`
+
+	// NextCursor Курсор следующей страницы прямых ответов, отсутствует на последней
+	NextCursor *string       `json:"nextCursor,omitempty"`
+	Post       Post          `json:"post"`
+	Replies    []ThreadReply `json:"replies"`
+}
+
+// ThreadReply defines model for ThreadReply.
+type ThreadReply struct {
+	Post    Post          `json:"post"`
+	Replies []ThreadReply `json:"replies"`
 }
 
 // User defines model for User.
@@ -71,7 +296,7 @@ type UserCreateRequest struct {
 	// Name Full name of the user
 	Name string `json:"name"`
 
-	// Password User password
+	// Password User password. Не короче настроенного минимума, не длиннее 72 байт, не содержит username и email, не встречается в базе утекших паролей
 	Password string `json:"password"`
 
 	// Username Unique username for the user
@@ -92,6 +317,21 @@ type UserUpdateRequest struct {
 	Username string `json:"username"`
 }
 
+// ValidationError defines model for ValidationError.
+type ValidationError struct {
+	Fields  []FieldError `json:"fields"`
+	Message string       `json:"message"`
+}
+
+// VerifyEmailRequest defines model for VerifyEmailRequest.
+type VerifyEmailRequest struct {
+	// Token Токен из ссылки в письме
+	Token string `json:"token"`
+}
+
+// PostID defines model for PostID.
+type PostID = int32
+
 // CommentsParams defines parameters for Comments.
 type CommentsParams struct {
 	// PostId ID of the post
@@ -108,28 +348,75 @@ type FollowJSONBody struct {
 	UserId *string `json:"userId,omitempty"`
 }
 
+// SearchHashtagsParams defines parameters for SearchHashtags.
+type SearchHashtagsParams struct {
+	// Prefix Начало тега, '#' в начале не обязателен
+	Prefix string `form:"prefix" json:"prefix"`
+
+	// Limit Количество подсказок, по умолчанию 10
+	Limit *int32 `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// HashtagPostsParams defines parameters for HashtagPosts.
+type HashtagPostsParams struct {
+	// Cursor Непрозрачный курсор из nextCursor, без него - первая страница
+	Cursor *string `form:"cursor,omitempty" json:"cursor,omitempty"`
+
+	// Limit Размер страницы, по умолчанию 20
+	Limit *int32 `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
 // LoginJSONBody defines parameters for Login.
 type LoginJSONBody struct {
 	Email    *openapi_types.Email `json:"email,omitempty"`
 	Password *string              `json:"password,omitempty"`
 }
 
+// OauthCallbackParams defines parameters for OauthCallback.
+type OauthCallbackParams struct {
+	Code  *string `form:"code,omitempty" json:"code,omitempty"`
+	State *string `form:"state,omitempty" json:"state,omitempty"`
+
+	// Error Код ошибки от провайдера, например access_denied
+	Error *string `form:"error,omitempty" json:"error,omitempty"`
+}
+
 // PostsParams defines parameters for Posts.
 type PostsParams struct {
 	// UserId ID of the user
 	UserId *int32 `form:"userId,omitempty" json:"userId,omitempty"`
+
+	// Cursor Непрозрачный курсор из nextCursor, без него - первая страница
+	Cursor *string `form:"cursor,omitempty" json:"cursor,omitempty"`
+
+	// Limit Размер страницы, по умолчанию 20
+	Limit *int32 `form:"limit,omitempty" json:"limit,omitempty"`
 }
 
 // CreatePostJSONBody defines parameters for CreatePost.
 type CreatePostJSONBody struct {
 	// Body Текст поста
 	Body string `json:"body"`
+
+	// InReplyToPostId Пост, на который это ответ
+	InReplyToPostId *int32 `json:"inReplyToPostId,omitempty"`
+
+	// QuotedPostId Цитируемый пост
+	QuotedPostId *int32 `json:"quotedPostId,omitempty"`
 }
 
-// LoginV2JSONBody defines parameters for LoginV2.
-type LoginV2JSONBody struct {
-	Password *string `json:"password,omitempty"`
-	Username *string `json:"username,omitempty"`
+// PostThreadParams defines parameters for PostThread.
+type PostThreadParams struct {
+	// Cursor Непрозрачный курсор из nextCursor, без него - первая страница ответов
+	Cursor *string `form:"cursor,omitempty" json:"cursor,omitempty"`
+
+	// Limit Количество прямых ответов, по умолчанию 20
+	Limit *int32 `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// RefreshTokenJSONBody defines parameters for RefreshToken.
+type RefreshTokenJSONBody struct {
+	RefreshToken *string `json:"refreshToken,omitempty"`
 }
 
 // UnfollowJSONRequestBody defines body for Unfollow for application/json ContentType.
@@ -141,17 +428,35 @@ type FollowJSONRequestBody FollowJSONBody
 // LoginJSONRequestBody defines body for Login for application/json ContentType.
 type LoginJSONRequestBody LoginJSONBody
 
+// LoginMfaJSONRequestBody defines body for LoginMfa for application/json ContentType.
+type LoginMfaJSONRequestBody = MFALoginRequest
+
+// ConfirmTotpJSONRequestBody defines body for ConfirmTotp for application/json ContentType.
+type ConfirmTotpJSONRequestBody = MFACodeRequest
+
+// DisableTotpJSONRequestBody defines body for DisableTotp for application/json ContentType.
+type DisableTotpJSONRequestBody = MFACodeRequest
+
 // CreatePostJSONRequestBody defines body for CreatePost for application/json ContentType.
 type CreatePostJSONRequestBody CreatePostJSONBody
 
 // CreateUserJSONRequestBody defines body for CreateUser for application/json ContentType.
 type CreateUserJSONRequestBody = UserCreateRequest
 
+// RefreshTokenJSONRequestBody defines body for RefreshToken for application/json ContentType.
+type RefreshTokenJSONRequestBody RefreshTokenJSONBody
+
+// CreatePersonalTokenJSONRequestBody defines body for CreatePersonalToken for application/json ContentType.
+type CreatePersonalTokenJSONRequestBody = PersonalTokenCreateRequest
+
 // UpdateUserJSONRequestBody defines body for UpdateUser for application/json ContentType.
 type UpdateUserJSONRequestBody = UserUpdateRequest
 
+// VerifyEmailJSONRequestBody defines body for VerifyEmail for application/json ContentType.
+type VerifyEmailJSONRequestBody = VerifyEmailRequest
+
 // LoginV2JSONRequestBody defines body for LoginV2 for application/json ContentType.
-type LoginV2JSONRequestBody LoginV2JSONBody
+type LoginV2JSONRequestBody = LoginRequest
 
 // ServerInterface represents all server handlers.
 type ServerInterface interface {
@@ -164,6 +469,12 @@ type ServerInterface interface {
 	// Процесс подписки на пользователя
 	// (POST /v1/follow)
 	Follow(ctx echo.Context) error
+	// Подсказки хэштегов
+	// (GET /v1/hashtags)
+	SearchHashtags(ctx echo.Context, params SearchHashtagsParams) error
+	// Посты с хэштегом
+	// (GET /v1/hashtags/{tag}/posts)
+	HashtagPosts(ctx echo.Context, tag string, params HashtagPostsParams) error
 	// Процесс отписки от пользователя
 	// (DELETE /v1/like/{postID})
 	Dislike(ctx echo.Context, postID int32) error
@@ -173,9 +484,30 @@ type ServerInterface interface {
 	// Аутентификация пользователя
 	// (POST /v1/login)
 	Login(ctx echo.Context) error
+	// Второй шаг входа - код из приложения или код восстановления
+	// (POST /v1/login/mfa)
+	LoginMfa(ctx echo.Context) error
 	// Logout user
 	// (POST /v1/logout)
 	Logout(ctx echo.Context) error
+	// Logout user on all devices
+	// (POST /v1/logout/all)
+	LogoutAll(ctx echo.Context) error
+	// Подтвердить подключение TOTP первым кодом
+	// (POST /v1/mfa/totp/confirm)
+	ConfirmTotp(ctx echo.Context) error
+	// Выключить TOTP
+	// (POST /v1/mfa/totp/disable)
+	DisableTotp(ctx echo.Context) error
+	// Начать подключение TOTP
+	// (POST /v1/mfa/totp/enroll)
+	EnrollTotp(ctx echo.Context) error
+	// Вход через внешнего OIDC провайдера
+	// (GET /v1/oauth/{provider}/authorize)
+	OauthAuthorize(ctx echo.Context, provider string) error
+	// Возврат от OIDC провайдера
+	// (GET /v1/oauth/{provider}/callback)
+	OauthCallback(ctx echo.Context, provider string, params OauthCallbackParams) error
 	// Получение информации о постах
 	// (GET /v1/posts)
 	Posts(ctx echo.Context, params PostsParams) error
@@ -185,9 +517,39 @@ type ServerInterface interface {
 	// Get post by ID
 	// (GET /v1/posts/{id})
 	PostById(ctx echo.Context, id int32) error
+	// Отменить репост
+	// (DELETE /v1/posts/{id}/repost)
+	Unrepost(ctx echo.Context, id PostID) error
+	// Репостнуть пост
+	// (POST /v1/posts/{id}/repost)
+	Repost(ctx echo.Context, id PostID) error
+	// Ветка обсуждения поста
+	// (GET /v1/posts/{id}/thread)
+	PostThread(ctx echo.Context, id PostID, params PostThreadParams) error
 	// Регистрация нового пользователя
 	// (POST /v1/register)
 	CreateUser(ctx echo.Context) error
+	// Завершить все сессии, кроме текущей
+	// (DELETE /v1/sessions)
+	RevokeOtherSessions(ctx echo.Context) error
+	// Активные сессии текущего пользователя
+	// (GET /v1/sessions)
+	ListSessions(ctx echo.Context) error
+	// Завершить сессию
+	// (DELETE /v1/sessions/{sessionID})
+	RevokeSession(ctx echo.Context, sessionID string) error
+	// Обновление пары токенов по refresh токену
+	// (POST /v1/token/refresh)
+	RefreshToken(ctx echo.Context) error
+	// Персональные токены текущего пользователя
+	// (GET /v1/tokens)
+	ListPersonalTokens(ctx echo.Context) error
+	// Создать персональный токен
+	// (POST /v1/tokens)
+	CreatePersonalToken(ctx echo.Context) error
+	// Отозвать персональный токен
+	// (DELETE /v1/tokens/{tokenID})
+	RevokePersonalToken(ctx echo.Context, tokenID string) error
 	// List all users
 	// (GET /v1/users)
 	ListUsers(ctx echo.Context) error
@@ -200,7 +562,13 @@ type ServerInterface interface {
 	// Get user by ID
 	// (GET /v1/users/{id})
 	GetUser(ctx echo.Context, id int32) error
-	// Тестовая аутентификация пользователя
+	// Подтверждение email по токену из письма
+	// (POST /v1/verify-email)
+	VerifyEmail(ctx echo.Context) error
+	// Повторная отправка письма для подтверждения email
+	// (POST /v1/verify-email/resend)
+	ResendVerificationEmail(ctx echo.Context) error
+	// Вход с полным описанием сессии
 	// (POST /v2/login)
 	LoginV2(ctx echo.Context) error
 }
@@ -214,6 +582,10 @@ type ServerInterfaceWrapper struct {
 func (w *ServerInterfaceWrapper) Comments(ctx echo.Context) error {
 	var err error
 
+	ctx.Set(CookieAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
 	// Parameter object where we will unmarshal all parameters from the context
 	var params CommentsParams
 	// ------------- Optional query parameter "postId" -------------
@@ -232,6 +604,10 @@ func (w *ServerInterfaceWrapper) Comments(ctx echo.Context) error {
 func (w *ServerInterfaceWrapper) Unfollow(ctx echo.Context) error {
 	var err error
 
+	ctx.Set(CookieAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
 	// Invoke the callback with all the unmarshaled arguments
 	err = w.Handler.Unfollow(ctx)
 	return err
@@ -241,11 +617,80 @@ func (w *ServerInterfaceWrapper) Unfollow(ctx echo.Context) error {
 func (w *ServerInterfaceWrapper) Follow(ctx echo.Context) error {
 	var err error
 
+	ctx.Set(CookieAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
 	// Invoke the callback with all the unmarshaled arguments
 	err = w.Handler.Follow(ctx)
 	return err
 }
 
+// SearchHashtags converts echo context to params.
+func (w *ServerInterfaceWrapper) SearchHashtags(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(CookieAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params SearchHashtagsParams
+	// ------------- Required query parameter "prefix" -------------
+
+	err = runtime.BindQueryParameter("form", true, true, "prefix", ctx.QueryParams(), &params.Prefix)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter prefix: %s", err))
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", ctx.QueryParams(), &params.Limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter limit: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.SearchHashtags(ctx, params)
+	return err
+}
+
+// HashtagPosts converts echo context to params.
+func (w *ServerInterfaceWrapper) HashtagPosts(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "tag" -------------
+	var tag string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "tag", ctx.Param("tag"), &tag, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter tag: %s", err))
+	}
+
+	ctx.Set(CookieAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params HashtagPostsParams
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cursor", ctx.QueryParams(), &params.Cursor)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter cursor: %s", err))
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", ctx.QueryParams(), &params.Limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter limit: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.HashtagPosts(ctx, tag, params)
+	return err
+}
+
 // Dislike converts echo context to params.
 func (w *ServerInterfaceWrapper) Dislike(ctx echo.Context) error {
 	var err error
@@ -257,6 +702,10 @@ func (w *ServerInterfaceWrapper) Dislike(ctx echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter postID: %s", err))
 	}
 
+	ctx.Set(CookieAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
 	// Invoke the callback with all the unmarshaled arguments
 	err = w.Handler.Dislike(ctx, postID)
 	return err
@@ -273,6 +722,10 @@ func (w *ServerInterfaceWrapper) Like(ctx echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter postID: %s", err))
 	}
 
+	ctx.Set(CookieAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
 	// Invoke the callback with all the unmarshaled arguments
 	err = w.Handler.Like(ctx, postID)
 	return err
@@ -287,19 +740,143 @@ func (w *ServerInterfaceWrapper) Login(ctx echo.Context) error {
 	return err
 }
 
+// LoginMfa converts echo context to params.
+func (w *ServerInterfaceWrapper) LoginMfa(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.LoginMfa(ctx)
+	return err
+}
+
 // Logout converts echo context to params.
 func (w *ServerInterfaceWrapper) Logout(ctx echo.Context) error {
 	var err error
 
+	ctx.Set(CookieAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
 	// Invoke the callback with all the unmarshaled arguments
 	err = w.Handler.Logout(ctx)
 	return err
 }
 
+// LogoutAll converts echo context to params.
+func (w *ServerInterfaceWrapper) LogoutAll(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(CookieAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.LogoutAll(ctx)
+	return err
+}
+
+// ConfirmTotp converts echo context to params.
+func (w *ServerInterfaceWrapper) ConfirmTotp(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(CookieAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.ConfirmTotp(ctx)
+	return err
+}
+
+// DisableTotp converts echo context to params.
+func (w *ServerInterfaceWrapper) DisableTotp(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(CookieAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.DisableTotp(ctx)
+	return err
+}
+
+// EnrollTotp converts echo context to params.
+func (w *ServerInterfaceWrapper) EnrollTotp(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(CookieAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.EnrollTotp(ctx)
+	return err
+}
+
+// OauthAuthorize converts echo context to params.
+func (w *ServerInterfaceWrapper) OauthAuthorize(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "provider" -------------
+	var provider string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "provider", ctx.Param("provider"), &provider, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter provider: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.OauthAuthorize(ctx, provider)
+	return err
+}
+
+// OauthCallback converts echo context to params.
+func (w *ServerInterfaceWrapper) OauthCallback(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "provider" -------------
+	var provider string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "provider", ctx.Param("provider"), &provider, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter provider: %s", err))
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params OauthCallbackParams
+	// ------------- Optional query parameter "code" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "code", ctx.QueryParams(), &params.Code)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter code: %s", err))
+	}
+
+	// ------------- Optional query parameter "state" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "state", ctx.QueryParams(), &params.State)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter state: %s", err))
+	}
+
+	// ------------- Optional query parameter "error" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "error", ctx.QueryParams(), &params.Error)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter error: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.OauthCallback(ctx, provider, params)
+	return err
+}
+
 // Posts converts echo context to params.
 func (w *ServerInterfaceWrapper) Posts(ctx echo.Context) error {
 	var err error
 
+	ctx.Set(CookieAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
 	// Parameter object where we will unmarshal all parameters from the context
 	var params PostsParams
 	// ------------- Optional query parameter "userId" -------------
@@ -309,6 +886,20 @@ func (w *ServerInterfaceWrapper) Posts(ctx echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter userId: %s", err))
 	}
 
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cursor", ctx.QueryParams(), &params.Cursor)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter cursor: %s", err))
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", ctx.QueryParams(), &params.Limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter limit: %s", err))
+	}
+
 	// Invoke the callback with all the unmarshaled arguments
 	err = w.Handler.Posts(ctx, params)
 	return err
@@ -318,6 +909,10 @@ func (w *ServerInterfaceWrapper) Posts(ctx echo.Context) error {
 func (w *ServerInterfaceWrapper) CreatePost(ctx echo.Context) error {
 	var err error
 
+	ctx.Set(CookieAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
 	// Invoke the callback with all the unmarshaled arguments
 	err = w.Handler.CreatePost(ctx)
 	return err
@@ -334,11 +929,91 @@ func (w *ServerInterfaceWrapper) PostById(ctx echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
 	}
 
+	ctx.Set(CookieAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
 	// Invoke the callback with all the unmarshaled arguments
 	err = w.Handler.PostById(ctx, id)
 	return err
 }
 
+// Unrepost converts echo context to params.
+func (w *ServerInterfaceWrapper) Unrepost(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id PostID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(CookieAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.Unrepost(ctx, id)
+	return err
+}
+
+// Repost converts echo context to params.
+func (w *ServerInterfaceWrapper) Repost(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id PostID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(CookieAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.Repost(ctx, id)
+	return err
+}
+
+// PostThread converts echo context to params.
+func (w *ServerInterfaceWrapper) PostThread(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id PostID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(CookieAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params PostThreadParams
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cursor", ctx.QueryParams(), &params.Cursor)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter cursor: %s", err))
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", ctx.QueryParams(), &params.Limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter limit: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.PostThread(ctx, id, params)
+	return err
+}
+
 // CreateUser converts echo context to params.
 func (w *ServerInterfaceWrapper) CreateUser(ctx echo.Context) error {
 	var err error
@@ -348,10 +1023,115 @@ func (w *ServerInterfaceWrapper) CreateUser(ctx echo.Context) error {
 	return err
 }
 
+// RevokeOtherSessions converts echo context to params.
+func (w *ServerInterfaceWrapper) RevokeOtherSessions(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(CookieAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.RevokeOtherSessions(ctx)
+	return err
+}
+
+// ListSessions converts echo context to params.
+func (w *ServerInterfaceWrapper) ListSessions(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(CookieAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.ListSessions(ctx)
+	return err
+}
+
+// RevokeSession converts echo context to params.
+func (w *ServerInterfaceWrapper) RevokeSession(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "sessionID" -------------
+	var sessionID string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "sessionID", ctx.Param("sessionID"), &sessionID, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter sessionID: %s", err))
+	}
+
+	ctx.Set(CookieAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.RevokeSession(ctx, sessionID)
+	return err
+}
+
+// RefreshToken converts echo context to params.
+func (w *ServerInterfaceWrapper) RefreshToken(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.RefreshToken(ctx)
+	return err
+}
+
+// ListPersonalTokens converts echo context to params.
+func (w *ServerInterfaceWrapper) ListPersonalTokens(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(CookieAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.ListPersonalTokens(ctx)
+	return err
+}
+
+// CreatePersonalToken converts echo context to params.
+func (w *ServerInterfaceWrapper) CreatePersonalToken(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(CookieAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.CreatePersonalToken(ctx)
+	return err
+}
+
+// RevokePersonalToken converts echo context to params.
+func (w *ServerInterfaceWrapper) RevokePersonalToken(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "tokenID" -------------
+	var tokenID string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "tokenID", ctx.Param("tokenID"), &tokenID, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter tokenID: %s", err))
+	}
+
+	ctx.Set(CookieAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.RevokePersonalToken(ctx, tokenID)
+	return err
+}
+
 // ListUsers converts echo context to params.
 func (w *ServerInterfaceWrapper) ListUsers(ctx echo.Context) error {
 	var err error
 
+	ctx.Set(CookieAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
 	// Invoke the callback with all the unmarshaled arguments
 	err = w.Handler.ListUsers(ctx)
 	return err
@@ -361,6 +1141,10 @@ func (w *ServerInterfaceWrapper) ListUsers(ctx echo.Context) error {
 func (w *ServerInterfaceWrapper) GetCurrentUser(ctx echo.Context) error {
 	var err error
 
+	ctx.Set(CookieAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
 	// Invoke the callback with all the unmarshaled arguments
 	err = w.Handler.GetCurrentUser(ctx)
 	return err
@@ -370,6 +1154,10 @@ func (w *ServerInterfaceWrapper) GetCurrentUser(ctx echo.Context) error {
 func (w *ServerInterfaceWrapper) UpdateUser(ctx echo.Context) error {
 	var err error
 
+	ctx.Set(CookieAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
 	// Invoke the callback with all the unmarshaled arguments
 	err = w.Handler.UpdateUser(ctx)
 	return err
@@ -386,11 +1174,37 @@ func (w *ServerInterfaceWrapper) GetUser(ctx echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
 	}
 
+	ctx.Set(CookieAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
 	// Invoke the callback with all the unmarshaled arguments
 	err = w.Handler.GetUser(ctx, id)
 	return err
 }
 
+// VerifyEmail converts echo context to params.
+func (w *ServerInterfaceWrapper) VerifyEmail(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.VerifyEmail(ctx)
+	return err
+}
+
+// ResendVerificationEmail converts echo context to params.
+func (w *ServerInterfaceWrapper) ResendVerificationEmail(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(CookieAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.ResendVerificationEmail(ctx)
+	return err
+}
+
 // LoginV2 converts echo context to params.
 func (w *ServerInterfaceWrapper) LoginV2(ctx echo.Context) error {
 	var err error
@@ -431,18 +1245,247 @@ func RegisterHandlersWithBaseURL(router EchoRouter, si ServerInterface, baseURL
 	router.GET(baseURL+"/v1/comments", wrapper.Comments)
 	router.DELETE(baseURL+"/v1/follow", wrapper.Unfollow)
 	router.POST(baseURL+"/v1/follow", wrapper.Follow)
+	router.GET(baseURL+"/v1/hashtags", wrapper.SearchHashtags)
+	router.GET(baseURL+"/v1/hashtags/:tag/posts", wrapper.HashtagPosts)
 	router.DELETE(baseURL+"/v1/like/:postID", wrapper.Dislike)
 	router.POST(baseURL+"/v1/like/:postID", wrapper.Like)
 	router.POST(baseURL+"/v1/login", wrapper.Login)
+	router.POST(baseURL+"/v1/login/mfa", wrapper.LoginMfa)
 	router.POST(baseURL+"/v1/logout", wrapper.Logout)
+	router.POST(baseURL+"/v1/logout/all", wrapper.LogoutAll)
+	router.POST(baseURL+"/v1/mfa/totp/confirm", wrapper.ConfirmTotp)
+	router.POST(baseURL+"/v1/mfa/totp/disable", wrapper.DisableTotp)
+	router.POST(baseURL+"/v1/mfa/totp/enroll", wrapper.EnrollTotp)
+	router.GET(baseURL+"/v1/oauth/:provider/authorize", wrapper.OauthAuthorize)
+	router.GET(baseURL+"/v1/oauth/:provider/callback", wrapper.OauthCallback)
 	router.GET(baseURL+"/v1/posts", wrapper.Posts)
 	router.POST(baseURL+"/v1/posts", wrapper.CreatePost)
 	router.GET(baseURL+"/v1/posts/:id", wrapper.PostById)
+	router.DELETE(baseURL+"/v1/posts/:id/repost", wrapper.Unrepost)
+	router.POST(baseURL+"/v1/posts/:id/repost", wrapper.Repost)
+	router.GET(baseURL+"/v1/posts/:id/thread", wrapper.PostThread)
 	router.POST(baseURL+"/v1/register", wrapper.CreateUser)
+	router.DELETE(baseURL+"/v1/sessions", wrapper.RevokeOtherSessions)
+	router.GET(baseURL+"/v1/sessions", wrapper.ListSessions)
+	router.DELETE(baseURL+"/v1/sessions/:sessionID", wrapper.RevokeSession)
+	router.POST(baseURL+"/v1/token/refresh", wrapper.RefreshToken)
+	router.GET(baseURL+"/v1/tokens", wrapper.ListPersonalTokens)
+	router.POST(baseURL+"/v1/tokens", wrapper.CreatePersonalToken)
+	router.DELETE(baseURL+"/v1/tokens/:tokenID", wrapper.RevokePersonalToken)
 	router.GET(baseURL+"/v1/users", wrapper.ListUsers)
 	router.GET(baseURL+"/v1/users/current", wrapper.GetCurrentUser)
 	router.PUT(baseURL+"/v1/users/current", wrapper.UpdateUser)
 	router.GET(baseURL+"/v1/users/:id", wrapper.GetUser)
+	router.POST(baseURL+"/v1/verify-email", wrapper.VerifyEmail)
+	router.POST(baseURL+"/v1/verify-email/resend", wrapper.ResendVerificationEmail)
 	router.POST(baseURL+"/v2/login", wrapper.LoginV2)
 
 }
+
+// Base64 encoded, gzipped, json marshaled Swagger object
+var swaggerSpec = []string{
+
+	"H4sIAAAAAAAC/+x9e2/cyJXvV6nLXCA2QHXLmkluoiC4VyPbM5pMrrV+JAuMDIPqrpYYs8kekm2P1hCg",
+	"x3jshRwra2SRRbIznslid/9ty2q7Lamlr1D8Rotz6sEiWeyHnuMg/9jqbrJYdc6pU+fxO4ePrFrQbAU+",
+	"9ePImn5ktZzQadKYhvhpPojiuavwV51GtdBtxW7gW9PW3FUSNEi8TEkriGLLtlz4tuXEy5Zt+U6TWtOW",
+	"W7dsK6RftN2Q1q3pOGxT24pqy7TpwICNIGw6MVznxx9MWbYVr7Qo/0iXaGitrq7Ky3Eqs0GzSf0Y5xgG",
+	"LRrGLsUfFoP6Cvwv7o/i0PWXrFXbqoXUiWl9Js48ru7E1LKLV7v1kWZlW7Diubrxie1WfYwntiMawoX/",
+	"O6QNa9r6UTXlRFUsvHonoqG81vjQVZ3Gn3OiI0X09eszU2OpldxVcwsWf0drMTzvWhgGYZHWTRpFzhId",
+	"Pg95oWns65TW52LaLA5/3/XrRWGDaZIJwo6STfaK7bMe22Od5GvWS7YJ67CdZIMdJmusY5OQimuTNdZl",
+	"R+wwWU82LNuiXzrNlgez4FeYuIHfD+EGbAcLVwpXGxk9EbtNI7flPR+tjMbzHEGRNGKWZUSdF6zJEtWN",
+	"aTP7x6CHK+asqoc4YeiswGeffhnPtsMoCItMYn9ONpO1ZB1YQZJ1ts+6bDfZTJ4n/8y67B0BTgCTWJ/1",
+	"kq+TLZuww2QjWU828d8NtpNssm6yQVifdQjnHR+E9WEAE0njsO3XQKyL0wFtUzZUbi6saxPWxUt6hB3i",
+	"TckmO2LdZI3ts06ynjwjbIew19r8N8mVycnJ/OC95DGRYscO2Y5N2B4sE4iSbLHugs+OkjW85YB1cBI9",
+	"tsM6uPCPrl+fJuwVO8ThujhL1uE3qlGTrfxke/g52Uie2QTXtyuuhCX0k60KYd+aCK2PowYn7C3rwJxZ",
+	"P3nCevArLL+y4Kf0XwwCjzp+UfWgeBll06VevUSj1II6NUjTv7NO8pT1WJ8dwkSAEqzLDpIt9g5pynaR",
+	"u8laspk8ZV3WhyvZa3ZIkMIdtoOz7yBNOvhdjx0AS0kcBPei5SCMbfzTC/wl/lfkgpKwSS3wY8f1o3ug",
+	"KOEw076iTcf1bLIYUqe2TOsmqWzAamFJqdZpOVH0MAiNl4+sUvm4NqeYPVDDfuJEy7GzVKQ2qI/ZoO3H",
+	"I551YpAcc/7Kuuw1iGqXvSU//tGPcXf0WY+9QQE84Or3NcoOyHg3o4KRUztik/Qse8iyYQq2NnHTej/9",
+	"7e2bNGoFfmTQf06tRqPodnCf+sW1fPrb24RfQGK8wsAhbYBrX7bckEYzcXGkGW0UQuE6B34h4jwY7ZCo",
+	"RWGjZKa/cbw2JY0gRLPrHydmb928PoHXkmXq1Glok8Wg7ddJHJB42Y0yy6qQGS8KSERj4vo4ADxpgk+2",
+	"FgT33ZIzqxHSaLlkRjdazhdtSsRF/EE2iVx/yaOkHQ0dcQAxb+pjHpOaOTnSxaCEp7kFl81WZ5NJGj8L",
+	"llx/LHXn+g8cz63fg/nSKLaJ/KIW0jr1Y9fxIhsYCjvgnhfU7tM64eqZNBvOPbXOsfSLbTUbzjB74NfX",
+	"Z2aXHc+j/hLlDIzDlZlGTE02wB/ZfrKdn+cESZ7gWQraIllH7b2fPIP/4WOX7SWbrA8K/YAdogo5xBNJ",
+	"GXY9ONwI20keg943eQpZTo+gIJFFNzm1i0xCJZ/RkPwbk9UoNbt+dbm6z81UDqtuKJ3rLRpFSOQh2m1k",
+	"5XX2+mgUhTK6fhhtvhEn01zd6LKCtCXryTrrgdHTY29J9cGVqrgnOpmLluNsOhH7jDSPmJxJYjJ71uDD",
+	"xctBPcpYKJ9bcRC38Lm14AENV+7hJrprp05D0frOeQfNhlMiKuwlGL1gfrE3YIaiTddHWw4+sD0w4GwC",
+	"5hvbhb/511JJsF1UK8ArD/ZCFdSWbdRmx5ObvO8qF2Ia0lb0K6N8UKelmkWq/8ETkLQ3DT9Yc5mPl58S",
+	"9Ja/Sta41AtzeB/VbRf9lm15nEjjmhto6IIIg20/vRSM613WITMfzV69NnH940/mPh3EkOEr1kheuvh5",
+	"GkaB73hqyNzSS6M9pdqCjq9gXHPgx3Oi+E403tN5mMwwWFQLWjnXfcjmMwWBcHg1mB4NGkrcWby0/Hgs",
+	"t9vYv4hTHjf8HuvwnYwbnODefpd19ndhf4OH/DbZAk94ZEtZUs9ogYDBgc6grl6SJ/jpVbLFnwi+7RP0",
+	"wp8RcTnbgTPiCBwXuMt4wijmGHQcuJ36kllnmoTUqdsYJo2mH4ZuTG3SCDwveKg+eu59Kj5Y9nF5nmX3",
+	"iCxGYXY870bDmv58SOhLv9tatfNCEZeo/j+hm/6E6w7Wlcoc4wyvMdYBugUkZaYdLweh+0/c0P+IOiH4",
+	"NDwgkjwDLx/jG9w23GMdKTIgSMl6sj3ckyyx2O/mifOZaxJ7vH30QFqeYIPZJwY38i2Ixop68zj56BOV",
+	"gXXDkX5GEXQ4wFreyu1gXoXS85uJhwdsEcLTg2jvSPJ7+MA38Q5w37JHemo02w5D6oOiDj9z7+vKV8W0",
+	"cpfdFEFb86WwcceJpnzRDmJanx8j0JzeYSTTf2FkrAdRsDQ6loa9R5hSCIwYZw08jD3OHe9ZUiRlaoY6",
+	"2ZVr28y0ZW8KKxpswai4d8P8z8fU+dlxTBMpdRuPYS3V+LYwiOH30q1KtvWINztk76StCUHYXTQh34DM",
+	"ZsyCt6yjwuIdQ5zZHmhx/BnjvmCNpu6dPGBAO6xBHJmfEDJkCVZAwahNZwQ/jGyHlBiEbssw1T+wXQiD",
+	"JOs2SdazhHrNDnPz76Elwr2gtzjXTpqNyKUdQCmC9fHWss226S1K/XF4DbthZklwe4TNlV6PS89uM20C",
+	"OitTiRogueajWLnso55xYrShO0oNbJrS7Ru356/5YeB55kR0ELecdrx8J3Sz0X/x/XS1Ck52NX7oxjEN",
+	"JxYbjWmg2/8TV1ZqQfP/RrQW0viXlUploT05OfVTN4raNPyldo+JXV+Es0bHb/7/fwyS9g83hVsHTjhJ",
+	"pwn27qIT0Z9+qIv74kpcEluBqZm3P9tDwd6QI34wJQ09zNA8SbMzO+BZwnYdaq6J59k6XdVSjfxZBlvb",
+	"ECLzazSKg7DMaodNtKelwTBrxNN/O8kWOyDo7eLq9nC7dlkfA5LwHdxp2aNJoTzVzzKvigo32QZjAJKR",
+	"ykYS6cgT51zHTJN7+ST0oPs4A9E2HLpTU6aKOaWPK5cNPrQxK/ZDWNMI67gjzKOcM+AGJb7AAxrONcvC",
+	"/yrOnRW5a/A1cer1EPJHAueDdpk9QkScO7c0jJSNWDQJ+TWuvzRXH8v+kcdtdr53fBfyUHNXy+Zabpua",
+	"owjX255H4KfcgMXNEAYN16PlFJYZ5NJJywtUIN38qNUSURgWqTkLBp+UZlrGJEeSiIZE/lwh7BvWlRoX",
+	"AsZdnvHn+u4wm/M/QBOzxw6STUBW2CJSsItoIbgOABX/ZwoMwA57JxxLgFjwiHOyJsxSxQ7WIyLVz0fa",
+	"Ec/tQshIRhww7w0jvoWxILANB+FTCQPp4Dz3hSYdITt0qvJiCA6p0e3RMk/AkDut+iAJE5rHwMhFN1gK",
+	"ndbyimWPrZjeo205iMwmmv4G8roY4irJECPMYwy8VoqtMeVkxsXr2fL5xrnT0G2soPYoFYiyOOBfU3+v",
+	"xyPE68kW2+eG1w7hQdfkGSSJjh3I40ZqO3TjlVtAHiGiGEmE6GL66brcjJ/+9rZlD0JyNMKgiRKAqScS",
+	"CpgJCULikJaI8WWwFuRSvNi6V6lULvObIW+FP0SVBf+2c59GpBXSGiT2a5TARsDxOQqDPFymPlkM4mXi",
+	"hJRE1I8rZKbkQU1nhQS+t0Jqjuct+MAGlKyIPFwOIkq+nMBwMHnoRGQpdPyYIjrEjX9B9GvdeDlox+pq",
+	"QWniiCULn2jBv9VutYIwJlHsNBr48Ij6deL4xG2KCXKMRpZwTr3p+mRmfm6auDGpOWHo0gjucmoxqXmO",
+	"21zwYQ7pxT+OiFu3iRvBDJZonQQ+oRDoIAIgQRy/Dj+HFBhP62RxBaZP/ciN3QdUWx0C13CvYEgBmZ/K",
+	"13Ict7g6AtpLERkgDQI880kct24g4TnTIhrDFJBeFTJ/49Ztm8zfgX9mbs9+grO9eu2za7evyQVECz64",
+	"NNSPXQQwwt0I2hEDNtuwSA7Z8etlGXaiyPYAk/FBY8E3YnvIpSAkKnVcJtOXbRLEyzR86EYUfl4hSzQm",
+	"H05+sOBLhLeCCnHtiapu4ncPNUCt03J/RVc4etv1G4bTYWZ+TmUBeIBjU+YHeBqyn3yFh/4BR/mynry8",
+	"EQZ+DPS4zd3hCjzWjdHJFl+BnFm29YCGPO5lXalMViaBx0GL+k7LtaatD/ArG+HqqCFgh+ph8yXu5iop",
+	"ghijxJ+jv6Fh5D8fDRr/RZuGKyndBPJ6PDz8XduSrMJpTk1O8pwvEAVn7LRaHgiUG/jV30U87pc+4WTZ",
+	"AGBodqWKIqu29eHkh4bwA4Cx/SAmDQCmZfQzEk7fdp/fhZySrqvxG1h01G42nXBF5AV0eWHdEnk55AGP",
+	"AzhOWJ/jaEG8wDDbS/Gum5ZtCbWH2HCHTxLkgbsofE0ejWlRIO744hp+ONEo/kjkZEbmSPboHBTOzp90",
+	"q/nSitUTCscICJeCANxqo25stD3SlsRAWbhiMqwckd+jdW5Nra5mWYtm/tc8BMoDFzIR28OPJB8ORXt7",
+	"P9nOMDGTW9UDFlnmXf876yTrTplxR+hSaayTsaVxWSc24jLHMuuK2QRHZj2eLETYOvwPAbIeTmobYqCt",
+	"kDbcLyHwLq7CaDrBibxmPbhESzX3AMT8BNewj0FTHdVv2TlhukWdsLb8iZzokAOCfaOefyif37E1HLX8",
+	"Vea7AbOwDfkMTjXQaGXHCi5yYNmVGYdtMrmNmRYOmujy6KEEau4iqzvI3D2MnR4SdMSBnE9EbPI5uTJZ",
+	"Mm3Pbbrx4MOw6XzpNttNa/onk7bVdH3+4cpFnZKC16OckuxlhkIQLoatNjV1ans871IaJ5FsCvF9p5Be",
+	"EBXB8AqGtPeSDYHJE1J0Ssd1ZukkeZz8PnkqpJ7vpZIDWO776qPYWVqtIoCmXAe8TCtYDnlAG/DDW/y8",
+	"1yppDipElC8gRglShP20BkdPEnIrA27MlzOMu1krCz77PhO476BOyhQhddI8KK980aeUrJMa5ge485zm",
+	"C3h6FQbewsExJ8CrdbIqSgjsPFJxmIL6z5RH5tJOXpMxrpIxF3sY1GOXp4PZWySGhIqyPS0TkqODLbmG",
+	"Qg0hwQmVwjFSu0QTcSpnVNHwCX+H0s2LiwxlbiXacOo0tOGVybNWh8NqBbHm0KBxuKRdgKb7pkSrcXlO",
+	"Ya6pMJ2SphP6J1nPK7mDciUHYJPqI/QGr64OcjauuhFcezznM7t5+dNOWJtdFKsPz9NmrQtyXLS3oUM3",
+	"S32Nz37YjDOQT6P0ycj8F0i3aB4365STT24JiEnpWdkcMfHn0/LbVIIsPbHykIyRcmJ6UqtY9nll6oPR",
+	"EkBmbzGn4P5D2CV9Xhi8iydKX1UJm2RWxdtEDRXrCKgzmjQIHdy0ztMt1Qs2zWtcx+P7KVpYUOYv0mt9",
+	"xDt+pfcAAPpPTZ7eIZOteDMa9CKzJ0Ega/yIQQcUIS57bD95zoNURJWxgemdfAXl1fyLCpnlwVmZYdzi",
+	"OwRQacl28pzbfvaCL4sjCOsnm7JADiBsPLi1zRHs/QzyHAzhnWzJDEzsQLhuXQlA45igBV/b4qdCxMHn",
+	"s0Y0nmZVx/JRSlteuI7XbhCcKYcRwqx3lET3cUVo+UpIi8DGgBWIg3Mr5OfnsLbv0T9+ymOPkJVWCWqY",
+	"5ibbleas6BPAjpItzjNb7U21MlgpCPkmD2BKEs3Nw6Vg2xzIHDh3HV5hqGIP4MB892OYgGcKcAPfhNLN",
+	"ibLazf8+jRrNdE17qFEKZrTW3SVndWXtqT+U7/fykzlzhFRFceuAY+TXDUdoveOcJEN0SKZQ65yjfsPU",
+	"659ljZeuuzIKSOgQkT4Cm1YragG4xbkpDE37sa5eQsR6nPGieFAeZXtyo6Cu7JqBtH07o0x3QDmDpPGd",
+	"JHzWi/JedO3IdfQPRIOlM0sei5mJGM57qmVe6EfzU6hI0k2kCVUMeQpFk7pyCtqxrpnyrQ8eBIARQF8g",
+	"pBHFtH0m7w/5ZOrXI+LGkUzP28T1a1677vpLeGumKwNpOE3XWylEhT7jczFronKXgN81plNwIv+aTzQd",
+	"LSVk1fG84cTk6AGRwwdItWiTQYnAoOPQZJE2gpDyTLwONeBeeUQczzNDMaIS0s543tlT17Z+Yhpzzo8B",
+	"h+SRiIYAMqFi05fQFUAWsL46feDWsGJUQ1SII0tQvtlwOJS9FvgNN2yWH7Oz/ILbsrj8TE5aveL6nA/a",
+	"bKGPSaG+KLH+c25ChfBDOdkaqEUKxY/STchVzAtrWVaFjCRMl1AK3Agz9hl0ymU+wHmcP5gx0AnDk/x9",
+	"if7Egl12qBRvmXOlTv8MlX9IJ/qqIVOyIa7c1U+6IjmgECVXoaBcuZH2bd2NnEWPlu/bq/yCH9i+NUBM",
+	"Bu2vZKvA+/djE5SvSdU/774fYv1C4wEXaZDdkWSUYqVV+dHOXkCGCF3hDqTUMEwgrEisRbJlpRO5c3MO",
+	"AN2qDCqFnxVNuomSUJPoMQm5vFFUupa7yyhmVWMjM+dyy79hu3IKZVvblNfj9Whqn57RMZerfDM7Dorw",
+	"HFb/lkcm/xa2XclZkhX1b+TpNFhvD5L9AFZefdQKgwdunYarVUWh8sT3d+gU91SyqyfLuXKZyM1M8PdI",
+	"xYjeifqHDrnkZBowQB8W0vCCh+D9z/9q9trlyoIfxU5MsWsHd6lxcB5+eYbuGmbUHvPnJtt6oYQIJQRu",
+	"vTaBw2Q7cqjoV6Zul+3j/oE1IQde4W7fhIKLZM20H27AImYU2YZluv+NHSTbZnJwp4+3wgQl8BoShhJh",
+	"WJKgEYwbmKLJh/zzCZkPeCh7MJslqqo471IwJnuZv1gzq7SiGmuIx8zZpLV0QwgFD9XzoOuNuauzJXMr",
+	"E3QAsC86tfsDAB5iuC5WN4KWEULkBwCjh8Do1Ww3KVgXF/jegHQe/iBPgh0O3RCVRe9SDMge6yqT80jG",
+	"le0FH75hr4TcC5XXVfgTHjqB2BRJ1rIM1PbpV7iyPj9z2G6F4FohfiWSII8HpAcGROhs0W22l1NcOLcD",
+	"3XQ+yKg71iVpzO1IsHk3U/XEDvm0oYWLRNh2CZT48/p21tEefogb+RXScEJG+GGGMN4ad0srCz4vSkPV",
+	"ss+PY9juW5o+zZ+VJhmDxVwSCEAoGcPRf4nsuucH8b0HUMLi0vrlCmHfG9IGUEqfjbwv+LpwZFvO2LnT",
+	"XTXUle2EAGOIk91n3eTr/Dp2uYL7vVBxIhNyxKNtHexrewCKW9XGJJtA1/zqZL9FfX3TZfFNLu0qy/Kc",
+	"sJ5hWkh1nE+pkp2VW7agY09LM9qPzBgd3t5x7PtQX4wJ7RGB8lSEFTigqN0MrYZ5iOhenfouNuc0TQt5",
+	"aJ3RCZFXLkOUu2ZRb/CFDtflQ4B5vCEYx0fD9t+XgHwiyiU3VbKuVEVfgtbvAsS3reXH+rwBdhqlvoyN",
+	"NdKHaX2tS8YGe17DDvYQRbcxAojwnAF97EVKuq7q+J3pc59sZcHXh2xvOnMBaAS4TGsSVNpMKx9Ckp2Y",
+	"0gRyoV0AsDDzOHCWZOMKlBJRdAtYuK7I6iYbeLTsaIazthbpKmlgLdGc4ZBrTdSPH12/Tgb0VceHZtqI",
+	"Qet2UOsDe7eD1KHu1HKtvGuo+CC8tct4votO6RtyHWkCI3tC7BDxIHGqdrGny2M8jTLSk2mk00m2F3xT",
+	"T3qgQrKZa01vC3NGt344OlKXM4S8qw760/AnNSn7kSCjhdp7k6ZTLZbGwSv9HR/6d3zoueBDzwAROmqp",
+	"mlKYyWMDVLQMVsh7P8zLniGnAYeTTQUNBTZ76rhQOD4N5f0CA28Hyo8Cxfu/jO2yLqT13xm30cuVpyMV",
+	"715w4RfvVzMEWnfIurrnimJaGrDDnZO+bqeXginkDjLbWM/4iB+U9SHRgtoFD+9S6lJlfVjhgmNHAkGg",
+	"SkhBzmrxHV95eZqCyS3mW2XSAxYSSziwpzHflidKqrLvsxQtQ79qPWGz1nT1kVtfLS1EBs5+tDJXH/1M",
+	"PsN3dN29ABGG70mdxo7rnWPt8cc0RkJCqf7c1QwjM5D+lINV8bKpgTXE4poCL02ESS+pihezXQj90dVU",
+	"DgSoYmUQjwdbGJlx6db6Vj2tV3CAyjeXXZZDeqmBi0SZyJq+uD4vd1QIKCIxt+LsyXLz5mnz8srZ81Ic",
+	"twZHcVxeHkvBq6Cq6d1u0n/uohvcHxDcHiw03xV84GdkuNAU93OsGgyWx6iLzQRFMGFPCBmEMwoe6dQk",
+	"Ect/gw2qsIcURjMgUqq56z1DmKG84d+Cj89OQxei/4EIbGAg4yUPcUEM4A2GHQ60EdlBdsQDMsF7totm",
+	"WI+1XGXh6cKCEx4Xvt9hH9n8Bt+EJtsndkRYAHy5dcghZiMRvyCSkDzJlBY65MIWRBtRRAdeZ2xFDEgY",
+	"/VvROfK4G/eCfNQcuU/TZTWXeg9qLHmWXuzURTqxQjaMCWMpbyPrpb9dd/dFupsho7uebEodr3BrBhM4",
+	"YzmFdMmNBGx4kMt7J6LhCVzeYaWH2Z6KJpr+US+5Em1uM14UL0xRGcBBtYTDPMIrZ15WyV4aJ/csTUA+",
+	"Van9LKpjcmy/KgUhCBC5ql5LHl9IWwS9lEt1hFS6Ldsk90goxQ20Urp2Li8kUqWqbYiowjpK+b/NDgYn",
+	"YL7L9hlIvh5DlOQu0ntjp25HHoafAi/yfdKfoZGhcjOQ4LBzRRTJJmYTuyQHwy7aw4C+vgE9xW6lL9ka",
+	"AdT3vf6eLn2CSNPjl6P+qbjWnWSds109ESLv8n1V2STVOyN4xx7UkiJNL5SWoqQmjQJ9sV6FsBc88SBK",
+	"wVDqAGkhWilkmtjrSBuZ8hf7rEty14pwZK/Y8D5ftRzF5Vw7vfNVb/RuBpapdJbOpnfHl4I/pNREyuvM",
+	"Hz0rWdhw1Ufir0Ipf1krzGQr+2ylQri9/lwmmkVvW55bEvlDnpZLNitEvvMoI6mFRQGm4Q3Pvu2yjgFC",
+	"UrJ7BXtGyvKr9Z8IADVEJeTf7aA5hieKO2SfIJFRPOHNHzFMl6QkT54PgvlhxUhVFOiUGzs3828EPI0I",
+	"/5AXMI5SgC7mpemPCmH/KsO0/VSVcdRQn/s1HBrKVRT4PQK3hA0kxaR4y0rrIgsjvxF7fFvhlPJqskzQ",
+	"ilQ5dsHiK2iMW3JYDLYfvi2o+a5YSLKlzYw752CYhIVZJ5tWRk7LG2KCxs685OpMj4niC7qGHhaZ9dpp",
+	"1MNw1mbeUlZ4GYys0M8CzqX+PEETElQf6/yd81p0I1PGP8aBVBrmzL+ETYcKFtZVglzfkUGVTEioWzg2",
+	"RFpSZ9cZOWsD3lk4UrLtylnOxBwxSLtgj4+RV6fXxUcQMHGI7fa37fSF+6pFgXz9I8lpv+3SRJkMyZr2",
+	"g/6S2KHHKrSLg/+HtFPitk1eSIdbOGLsM7BvUsngCW/YkceQjWFj5+0aU35FPPs0eALzGnyC3MErzqNn",
+	"o3xr3LCGjTPEc6MYcqd89qdQvexiN29PDFgaAMOfq9p71oxU+5jG2hsKrQtoHjur10zXndg5lSKfY+fb",
+	"ITObKeOWuWEjlqZtICl/ycUZxxWzb9IwkJVfUEeK5l88cfEdg+F7It6YSCJVr+6tnArzjYoLH1mW1ePU",
+	"kvQZrIEGAyo+pnInHQPj+B7hKQZydhiewsSLE+MpeNOFwXgKxPKsTKhObWZnWXs1yRltYcPLT45btHxN",
+	"FXoUM+IXYeH99dguK7pQaMMqWP4uwuhey3C1VhM02Hd9aS6HZV29Lkb3U2VbFvXimI5RZqrYQ6U+KM4C",
+	"v+tIslSMMpycOpf+B3I1InMtjet97oy+P+W0XMhlTGOArP/8nMkqp9QXAaod1i8QO9mGqnC45dybG2lv",
+	"wx2ltVF2A8mxRNdEfU17vA2/2iyZd7CY69Blu8uSw3Wq2KsznyDX37QPWxk+pl0JtTC2MQoxeuzDJqyT",
+	"TcdNYIfDA1F708c3yPUwDt6VwBa9sF62Yiwrs8Sv9rAK2eZgmb1Mg0UTsgQ7wP1m6ozOo4vsL4fPVu/U",
+	"NXcD0GPpsm9L57S7xuFEyrc9L99z/QdwCN6rhbQOitDxIlseYM2Gc0/SzdYKOHnx5MD+ndNEYIx2Va2X",
+	"KInvppt7n1fTnvapPta6hfTZpuB8efvN01bOI01ZlrJ6Qe0+MqSoILXK8xC0MSrjrIa9pOW72A7nzWtk",
+	"xiEqwi7R9PjlEQve1yVD+wIWJ0LNAuTNDrT8D+tZq+NbyPBg9HJN3gf7i0C8aSGYdQEOw1r+XZUF5Ani",
+	"tAso7ELLttqhJ155Nl2tekHN8ZaDKJ7+2eTPJqtOy7VW767+zwAZGyXjDZoAAA==",
+}
+
+// GetSwagger returns the content of the embedded swagger specification file
+// or error if failed to decode
+func decodeSpec() ([]byte, error) {
+	zipped, err := base64.StdEncoding.DecodeString(strings.Join(swaggerSpec, ""))
+	if err != nil {
+		return nil, fmt.Errorf("error base64 decoding spec: %w", err)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(zipped))
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing spec: %w", err)
+	}
+	var buf bytes.Buffer
+	_, err = buf.ReadFrom(zr)
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing spec: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+var rawSpec = decodeSpecCached()
+
+// a naive cached of a decoded swagger spec
+func decodeSpecCached() func() ([]byte, error) {
+	data, err := decodeSpec()
+	return func() ([]byte, error) {
+		return data, err
+	}
+}
+
+// Constructs a synthetic filesystem for resolving external references when loading openapi specifications.
+func PathToRawSpec(pathToFile string) map[string]func() ([]byte, error) {
+	res := make(map[string]func() ([]byte, error))
+	if len(pathToFile) > 0 {
+		res[pathToFile] = rawSpec
+	}
+
+	return res
+}
+
+// GetSwagger returns the Swagger specification corresponding to the generated code
+// in this file. The external references of Swagger specification are resolved.
+// The logic of resolving external references is tightly connected to "import-mapping" feature.
+// Externally referenced files must be embedded in the corresponding golang packages.
+// Urls can be supported but this task was out of the scope.
+func GetSwagger() (swagger *openapi3.T, err error) {
+	resolvePath := PathToRawSpec("")
+
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = true
+	loader.ReadFromURIFunc = func(loader *openapi3.Loader, url *url.URL) ([]byte, error) {
+		pathToFile := url.String()
+		pathToFile = path.Clean(pathToFile)
+		getSpec, ok := resolvePath[pathToFile]
+		if !ok {
+			err1 := fmt.Errorf("path not found: %s", pathToFile)
+			return nil, err1
+		}
+		return getSpec()
+	}
+	var specData []byte
+	specData, err = rawSpec()
+	if err != nil {
+		return
+	}
+	swagger, err = loader.LoadFromData(specData)
+	if err != nil {
+		return
+	}
+	return
+}
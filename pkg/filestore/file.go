@@ -0,0 +1,82 @@
+package filestore
+
+import (
+	"encoding/json"
+	"github.com/pkg/errors"
+	"os"
+	"path/filepath"
+)
+
+var ErrNoPath = errors.New("file path is empty")
+
+// File хранит состояние небольшого репозитория целиком в одном JSON файле.
+// Файл перезаписывается атомарно: новое содержимое пишется во временный файл рядом и переименовывается,
+// поэтому после падения на диске остается либо старая, либо новая версия.
+type File[T any] struct {
+	path string
+}
+
+// Load читает состояние. Отсутствующий файл - пустое состояние.
+func (f *File[T]) Load() (T, error) {
+	var state T
+
+	raw, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return state, errors.Wrap(err, "read state")
+	}
+
+	err = json.Unmarshal(raw, &state)
+	if err != nil {
+		return state, errors.Wrapf(err, "decode %s", f.path)
+	}
+
+	return state, nil
+}
+
+func (f *File[T]) Save(state T) error {
+	raw, err := json.Marshal(state)
+	if err != nil {
+		return errors.Wrap(err, "encode state")
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*")
+	if err != nil {
+		return errors.Wrap(err, "create temp file")
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(raw)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return errors.Wrap(err, "write temp file")
+	}
+
+	err = os.Rename(tmp.Name(), f.path)
+	if err != nil {
+		return errors.Wrap(err, "replace state file")
+	}
+
+	return nil
+}
+
+// New создает каталог файла. Файлы содержат секреты, поэтому доступны только владельцу.
+func New[T any](path string) (*File[T], error) {
+	if len(path) == 0 {
+		return nil, ErrNoPath
+	}
+
+	err := os.MkdirAll(filepath.Dir(path), 0o700)
+	if err != nil {
+		return nil, errors.Wrap(err, "create state dir")
+	}
+
+	return &File[T]{path: path}, nil
+}
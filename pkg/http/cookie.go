@@ -0,0 +1,55 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// CookieConfig атрибуты cookie, которые выставляет сервер
+type CookieConfig struct {
+	// Domain пусто - cookie только для текущего хоста
+	Domain string
+	// Secure отправлять cookie только по HTTPS
+	Secure bool
+	// SameSite lax, strict или none. По умолчанию lax, none требует Secure
+	SameSite string
+}
+
+func (c CookieConfig) Validate() error {
+	sameSite, err := c.sameSite()
+	if err != nil {
+		return err
+	}
+
+	if sameSite == http.SameSiteNoneMode && !c.Secure {
+		return fmt.Errorf("cookie sameSite none requires secure")
+	}
+
+	return nil
+}
+
+// Apply проставляет в cookie настроенные атрибуты. Явно заданный у cookie SameSite не перезаписывается.
+func (c CookieConfig) Apply(cookie *http.Cookie) *http.Cookie {
+	cookie.Domain = c.Domain
+	cookie.Secure = c.Secure
+
+	if cookie.SameSite == 0 {
+		cookie.SameSite, _ = c.sameSite()
+	}
+
+	return cookie
+}
+
+func (c CookieConfig) sameSite() (http.SameSite, error) {
+	switch strings.ToLower(c.SameSite) {
+	case "", "lax":
+		return http.SameSiteLaxMode, nil
+	case "strict":
+		return http.SameSiteStrictMode, nil
+	case "none":
+		return http.SameSiteNoneMode, nil
+	default:
+		return 0, fmt.Errorf("unknown cookie sameSite %q", c.SameSite)
+	}
+}
@@ -0,0 +1,67 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"time"
+	"twitter-bff/domain/models"
+)
+
+type AuditLogger interface {
+	Record(ctx context.Context, entry models.AuditEntry) error
+}
+
+// ImpersonationMiddleware записывает в журнал каждый запрос с токеном имперсонации
+// и отклоняет операции, помеченные в спецификации x-sensitive. Ставится после AuthMiddleware.
+func ImpersonationMiddleware(operations Operations, audit AuditLogger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			jUser, _ := c.Get(models.JWTUserContextKey).(models.JWTUser)
+			if !jUser.IsImpersonated() {
+				return next(c)
+			}
+
+			entry := models.AuditEntry{
+				Time:    time.Now(),
+				ActorID: jUser.ActorID,
+				UserID:  jUser.UserID,
+				Action:  models.AuditActionImpersonatedRequest,
+				Method:  c.Request().Method,
+				Path:    c.Request().URL.RequestURI(),
+				IP:      c.RealIP(),
+			}
+
+			operation, _ := operations.Lookup(c.Request().Method, c.Path())
+			if operation.Sensitive {
+				entry.Action = models.AuditActionImpersonationBlocked
+				entry.Status = http.StatusForbidden
+
+				err := audit.Record(c.Request().Context(), entry)
+				if err != nil {
+					return err
+				}
+
+				return c.JSON(http.StatusForbidden, map[string]string{
+					"message": "Operation is not available while impersonating a user",
+				})
+			}
+
+			handlerErr := next(c)
+
+			entry.Status = c.Response().Status
+			var httpErr *echo.HTTPError
+			if errors.As(handlerErr, &httpErr) {
+				entry.Status = httpErr.Code
+			}
+
+			err := audit.Record(c.Request().Context(), entry)
+			if err != nil {
+				return errors.Join(handlerErr, err)
+			}
+
+			return handlerErr
+		}
+	}
+}
@@ -1,49 +1,228 @@
 package http
 
 import (
+	"context"
+	"crypto/subtle"
 	"errors"
-	"github.com/golang-jwt/jwt/v5"
+	"fmt"
 	"github.com/labstack/echo/v4"
 	"net/http"
+	"strings"
 	"twitter-bff/domain/models"
+	"twitter-bff/helpers"
+	"twitter-bff/pkg/keys"
 )
 
-func JwtCookieMiddleware(secretKey string, skipPaths ...string) echo.MiddlewareFunc {
+const (
+	bearerScheme = "Bearer"
+	authRealm    = "twitter-bff"
+)
+
+type RevocationChecker interface {
+	IsRevoked(ctx context.Context, jUser models.JWTUser) (bool, error)
+}
+
+type PersonalTokenAuthenticator interface {
+	Authenticate(ctx context.Context, raw string) (models.JWTUser, error)
+}
+
+// AuthMiddleware проверяет токен согласно требованиям операции из спецификации и кладет
+// models.JWTUser в контекст запроса. Маршруты вне спецификации пропускаются без проверки.
+//
+// Токен принимается из заголовка Authorization: Bearer или из cookie user-jwt.
+// Если переданы оба, используется заголовок: явно переданные учетные данные важнее cookie,
+// которую браузер подставляет автоматически.
+//
+// Изменяющие запросы, аутентифицированные cookie, дополнительно требуют заголовок X-CSRF-Token
+// со значением, хэш которого записан в токене (см. checkCSRF). С Bearer заголовком
+// браузер сам запрос не подпишет, поэтому такие запросы от проверки освобождены.
+//
+// Персональные токены (с префиксом tbp_) принимаются только в заголовке и только для операций,
+// право на которые выдано токену (расширение x-scope в спецификации).
+func AuthMiddleware(
+	operations Operations,
+	keySet *keys.KeySet,
+	revocations RevocationChecker,
+	personalTokens PersonalTokenAuthenticator,
+) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			for _, path := range skipPaths {
-				if c.Path() == path {
+			operation, ok := operations.Lookup(c.Request().Method, c.Path())
+			if !ok || operation.Security == SecurityNone {
+				return next(c)
+			}
+
+			rawToken, fromCookie, err := tokenFromRequest(c)
+			if err != nil {
+				return Unauthorized(c, "invalid_request", err.Error())
+			}
+
+			if len(rawToken) == 0 {
+				if operation.Security == SecurityOptional {
 					return next(c)
 				}
+
+				return Unauthorized(c, "", "Authentication required")
 			}
 
-			cookie, err := c.Cookie(models.JWTCookieName)
-			if errors.Is(err, http.ErrNoCookie) {
+			if !fromCookie && strings.HasPrefix(rawToken, models.PersonalTokenPrefix) {
+				jUser, err := personalTokens.Authenticate(c.Request().Context(), rawToken)
+				if errors.Is(err, models.ErrInvalidPersonalToken) {
+					return Unauthorized(c, "invalid_token", "Invalid or expired token")
+				}
+				if err != nil {
+					return c.JSON(http.StatusInternalServerError, map[string]string{
+						"message": "Failed to check personal access token",
+					})
+				}
+
+				// токены удаляются при выходе везде и смене пароля, проверка отзыва закрывает
+				// запросы, которые успели найти токен до удаления
+				revoked, err := revocations.IsRevoked(c.Request().Context(), jUser)
+				if err != nil {
+					return c.JSON(http.StatusInternalServerError, map[string]string{
+						"message": "Failed to check token revocation",
+					})
+				}
+
+				if revoked {
+					return Unauthorized(c, "invalid_token", "Token has been revoked")
+				}
+
+				if !jUser.HasScope(operation.Scope) {
+					return insufficientScope(c, operation.Scope)
+				}
+
+				c.Set(models.JWTUserContextKey, jUser)
 				return next(c)
 			}
+
+			claims, err := keySet.Parse(rawToken, models.AccessTokenAudience)
+			if err != nil {
+				return Unauthorized(c, "invalid_token", "Invalid or expired token")
+			}
+
+			jUser, err := models.JWTUserFromClaims(claims)
+			if err != nil {
+				return Unauthorized(c, "invalid_token", "Invalid token claims")
+			}
+
+			revoked, err := revocations.IsRevoked(c.Request().Context(), jUser)
 			if err != nil {
-				return c.JSON(http.StatusBadRequest, map[string]string{
-					"message": "Invalid cookie",
+				return c.JSON(http.StatusInternalServerError, map[string]string{
+					"message": "Failed to check token revocation",
 				})
 			}
 
-			token, err := jwt.Parse(cookie.Value, func(token *jwt.Token) (interface{}, error) {
-				if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-					return nil, echo.NewHTTPError(http.StatusUnauthorized, "Unexpected signing method")
-				}
-				return []byte(secretKey), nil
-			})
+			if revoked {
+				return Unauthorized(c, "invalid_token", "Token has been revoked")
+			}
 
-			if err != nil || !token.Valid {
-				return c.JSON(http.StatusUnauthorized, map[string]string{
-					"message": "Invalid or expired token",
+			if fromCookie && !checkCSRF(c, jUser) {
+				return c.JSON(http.StatusForbidden, map[string]string{
+					"message": "Missing or invalid CSRF token",
 				})
 			}
 
 			// Передаем данные токена дальше
-			claims := token.Claims.(jwt.MapClaims)
-			c.Set("user", claims)
+			c.Set(models.JWTUserContextKey, jUser)
 			return next(c)
 		}
 	}
 }
+
+// PermissionMiddleware проверяет право из расширения x-permission операции по ролям пользователя.
+// Ставится после AuthMiddleware, которая кладет пользователя в контекст.
+func PermissionMiddleware(operations Operations) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			operation, ok := operations.Lookup(c.Request().Method, c.Path())
+			if !ok || len(operation.Permission) == 0 {
+				return next(c)
+			}
+
+			jUser, _ := c.Get(models.JWTUserContextKey).(models.JWTUser)
+			if jUser.UserID == 0 {
+				return Unauthorized(c, "", "Authentication required")
+			}
+
+			if !jUser.HasPermission(operation.Permission) {
+				return c.JSON(http.StatusForbidden, map[string]string{
+					"message": fmt.Sprintf("Permission %s required", operation.Permission),
+				})
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// Unauthorized отвечает 401 с заголовком WWW-Authenticate по RFC 6750.
+// Пустой errCode означает, что учетные данные не были переданы вовсе.
+func Unauthorized(c echo.Context, errCode, message string) error {
+	challenge := fmt.Sprintf(`%s realm="%s"`, bearerScheme, authRealm)
+	if len(errCode) != 0 {
+		challenge += fmt.Sprintf(`, error="%s", error_description="%s"`, errCode, message)
+	}
+
+	c.Response().Header().Set(echo.HeaderWWWAuthenticate, challenge)
+
+	return c.JSON(http.StatusUnauthorized, map[string]string{
+		"message": message,
+	})
+}
+
+// insufficientScope отвечает 403 по RFC 6750, если у персонального токена нет нужного права
+func insufficientScope(c echo.Context, scope string) error {
+	message := "Operation is not available to personal access tokens"
+	challenge := fmt.Sprintf(`%s realm="%s", error="insufficient_scope"`, bearerScheme, authRealm)
+	if len(scope) != 0 {
+		message = fmt.Sprintf("Token lacks the %s scope", scope)
+		challenge += fmt.Sprintf(`, scope="%s"`, scope)
+	}
+
+	c.Response().Header().Set(echo.HeaderWWWAuthenticate, challenge)
+
+	return c.JSON(http.StatusForbidden, map[string]string{
+		"message": message,
+	})
+}
+
+// checkCSRF сверяет заголовок X-CSRF-Token с хэшем из claim csrf токена.
+// Безопасные методы не проверяются: они не должны менять состояние.
+func checkCSRF(c echo.Context, jUser models.JWTUser) bool {
+	switch c.Request().Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+
+	header := c.Request().Header.Get(models.CSRFHeaderName)
+	if len(header) == 0 || len(jUser.CSRFHash) == 0 {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(helpers.HashToken(header)), []byte(jUser.CSRFHash)) == 1
+}
+
+// tokenFromRequest возвращает токен и признак того, что он взят из cookie
+func tokenFromRequest(c echo.Context) (string, bool, error) {
+	header := c.Request().Header.Get(echo.HeaderAuthorization)
+	if len(header) != 0 {
+		scheme, token, ok := strings.Cut(header, " ")
+		if !ok || !strings.EqualFold(scheme, bearerScheme) || len(strings.TrimSpace(token)) == 0 {
+			return "", false, errors.New("authorization header must use the Bearer scheme")
+		}
+
+		return strings.TrimSpace(token), false, nil
+	}
+
+	cookie, err := c.Cookie(models.JWTCookieName)
+	if errors.Is(err, http.ErrNoCookie) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, errors.New("invalid cookie")
+	}
+
+	return cookie.Value, true, nil
+}
@@ -0,0 +1,100 @@
+package http
+
+import (
+	"github.com/getkin/kin-openapi/openapi3"
+	"strings"
+)
+
+type Security int8
+
+const (
+	// SecurityRequired операция доступна только с валидным токеном
+	SecurityRequired Security = iota
+	// SecurityOptional токен не обязателен, но если передан, он должен быть валидным
+	SecurityOptional
+	// SecurityNone токен не проверяется вовсе
+	SecurityNone
+)
+
+// scopeExtension расширение операции в спецификации с правом, которое нужно персональному
+// токену для ее вызова. Операции без него персональным токенам недоступны.
+const scopeExtension = "x-scope"
+
+// permissionExtension расширение операции с правом, которое пользователь должен получить от своих ролей
+const permissionExtension = "x-permission"
+
+// sensitiveExtension помечает операции, недоступные администратору, вошедшему от имени пользователя
+const sensitiveExtension = "x-sensitive"
+
+type Operation struct {
+	Security Security
+	// Scope право персонального токена, пусто - операция доступна только в сессии
+	Scope string
+	// Permission право, которое проверяет PermissionMiddleware, пусто - проверки нет
+	Permission string
+	// Sensitive операция закрыта для токенов имперсонации
+	Sensitive bool
+}
+
+// Operations требования к аутентификации по ключу "METHOD /echo/path"
+type Operations map[string]Operation
+
+func (o Operations) Lookup(method, path string) (Operation, bool) {
+	operation, ok := o[operationKey(method, path)]
+
+	return operation, ok
+}
+
+// NewOperations строит требования из секции security спецификации: у операции своя секция
+// перекрывает глобальную, пустой список означает "без аутентификации",
+// а пустое требование {} среди прочих - необязательную аутентификацию.
+func NewOperations(swagger *openapi3.T, baseURL string) Operations {
+	operations := make(Operations)
+
+	for path, pathItem := range swagger.Paths.Map() {
+		for method, operation := range pathItem.Operations() {
+			requirements := swagger.Security
+			if operation.Security != nil {
+				requirements = *operation.Security
+			}
+
+			scope, _ := operation.Extensions[scopeExtension].(string)
+			permission, _ := operation.Extensions[permissionExtension].(string)
+			sensitive, _ := operation.Extensions[sensitiveExtension].(bool)
+
+			operations[operationKey(method, baseURL+echoPath(path))] = Operation{
+				Security:   securityOf(requirements),
+				Scope:      scope,
+				Permission: permission,
+				Sensitive:  sensitive,
+			}
+		}
+	}
+
+	return operations
+}
+
+func securityOf(requirements openapi3.SecurityRequirements) Security {
+	if len(requirements) == 0 {
+		return SecurityNone
+	}
+
+	for _, requirement := range requirements {
+		if len(requirement) == 0 {
+			return SecurityOptional
+		}
+	}
+
+	return SecurityRequired
+}
+
+// echoPath переводит шаблон пути OpenAPI /posts/{id} в формат echo /posts/:id
+func echoPath(path string) string {
+	replacer := strings.NewReplacer("{", ":", "}", "")
+
+	return replacer.Replace(path)
+}
+
+func operationKey(method, path string) string {
+	return method + " " + path
+}
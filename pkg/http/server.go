@@ -8,8 +8,11 @@ import (
 	"github.com/samber/lo"
 	"go.uber.org/zap"
 	"net/http"
+	"twitter-bff/pkg/keys"
 )
 
+const JWKSPath = "/.well-known/jwks.json"
+
 type Context interface {
 	echo.Context
 }
@@ -19,8 +22,11 @@ type HandlerFunc func(Context) error
 type MiddlewareFunc func(HandlerFunc) HandlerFunc
 
 type Config struct {
-	Addr      string
-	SecretKey string
+	Addr string
+	// TrustProxy брать IP клиента из X-Forwarded-For. Включать только за доверенным прокси,
+	// иначе клиент может подставить любой адрес и обойти ограничение попыток входа по IP.
+	TrustProxy bool
+	Cookie     CookieConfig
 }
 
 type Server struct {
@@ -110,7 +116,16 @@ func (s *Server) Echo() *echo.Echo {
 	return s.server
 }
 
-func NewServer(config Config, v *validator.Validate, logger *zap.Logger) *Server {
+func NewServer(
+	config Config,
+	v *validator.Validate,
+	operations Operations,
+	keySet *keys.KeySet,
+	revocations RevocationChecker,
+	personalTokens PersonalTokenAuthenticator,
+	audit AuditLogger,
+	logger *zap.Logger,
+) *Server {
 	s := echo.New()
 	s.HideBanner = true
 	s.Use(middleware.RequestLoggerWithConfig(middleware.RequestLoggerConfig{
@@ -127,8 +142,14 @@ func NewServer(config Config, v *validator.Validate, logger *zap.Logger) *Server
 		HandleError: true,
 	}))
 	s.Use(middleware.Recover())
-	s.Use(JwtCookieMiddleware(config.SecretKey, "/api/v1/login", "/api/v1/register"))
+	s.Use(AuthMiddleware(operations, keySet, revocations, personalTokens))
+	s.Use(ImpersonationMiddleware(operations, audit))
+	s.Use(PermissionMiddleware(operations))
 	s.Validator = &customValidator{validator: v}
+	s.IPExtractor = echo.ExtractIPDirect()
+	if config.TrustProxy {
+		s.IPExtractor = echo.ExtractIPFromXFFHeader()
+	}
 
 	return &Server{
 		config: config,
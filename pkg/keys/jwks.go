@@ -0,0 +1,73 @@
+package keys
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"github.com/samber/lo"
+	"sort"
+)
+
+// JWK публичная часть ключа в формате RFC 7517
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS отдает все ключи проверки, чтобы сервисы users и posts могли проверять токены сами.
+// Access токен отличается от остальных подписанных токенов только aud "twitter-bff:access",
+// сервис должен его проверять.
+func (k *KeySet) JWKS() JWKS {
+	ids := lo.Keys(k.keys)
+	sort.Strings(ids)
+
+	return JWKS{
+		Keys: lo.Map(ids, func(id string, _ int) JWK {
+			return publicKeyJWK(k.keys[id])
+		}),
+	}
+}
+
+func publicKeyJWK(k *key) JWK {
+	jwk := JWK{
+		KeyID:     k.id,
+		Use:       "sig",
+		Algorithm: k.method.Alg(),
+	}
+
+	switch public := k.public.(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = base64URL(public.N.Bytes())
+		jwk.E = base64URL(bigEndian(public.E))
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = base64URL(public)
+	}
+
+	return jwk
+}
+
+func base64URL(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func bigEndian(n int) []byte {
+	var b []byte
+	for ; n > 0; n >>= 8 {
+		b = append([]byte{byte(n)}, b...)
+	}
+
+	return b
+}
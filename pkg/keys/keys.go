@@ -0,0 +1,236 @@
+package keys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"os"
+)
+
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+var (
+	ErrUnknownKeyID       = errors.New("unknown key id")
+	ErrUnexpectedAlg      = errors.New("unexpected signing method")
+	ErrNoActiveKey        = errors.New("no active signing key")
+	ErrManyActiveKeys     = errors.New("more than one active signing key")
+	ErrUnsupportedAlg     = errors.New("unsupported algorithm")
+	ErrMissingPrivateKey  = errors.New("active key has no private key")
+	ErrMissingKeyMaterial = errors.New("key has neither private nor public key")
+)
+
+// KeyConfig описывает один ключ. Ключ можно задать PEM строкой (удобно через env)
+// или путем к файлу. Ключи без приватной части используются только для проверки подписи.
+type KeyConfig struct {
+	ID             string
+	Algorithm      string
+	PrivateKey     string
+	PrivateKeyFile string
+	PublicKey      string
+	PublicKeyFile  string
+	Active         bool
+}
+
+type Config struct {
+	Keys []KeyConfig
+}
+
+type key struct {
+	id      string
+	method  jwt.SigningMethod
+	private crypto.PrivateKey
+	public  crypto.PublicKey
+}
+
+// KeySet подписывает токены активным ключом и проверяет подпись любым известным ключом,
+// что позволяет ротировать ключи без разлогина пользователей.
+type KeySet struct {
+	signer *key
+	keys   map[string]*key
+}
+
+func (k *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.signer.method, claims)
+	token.Header["kid"] = k.signer.id
+
+	return token.SignedString(k.signer.private)
+}
+
+func (k *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	verifier, ok := k.keys[kid]
+	if !ok {
+		return nil, errors.Wrap(ErrUnknownKeyID, kid)
+	}
+
+	if token.Method.Alg() != verifier.method.Alg() {
+		return nil, ErrUnexpectedAlg
+	}
+
+	return verifier.public, nil
+}
+
+// Parse проверяет подпись и срок действия токена, выпущенного для audience
+func (k *KeySet) Parse(rawToken, audience string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+
+	_, err := jwt.ParseWithClaims(
+		rawToken,
+		claims,
+		k.Keyfunc,
+		jwt.WithValidMethods(k.Algorithms()),
+		jwt.WithAudience(audience),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func (k *KeySet) Algorithms() []string {
+	return []string{AlgorithmRS256, AlgorithmEdDSA}
+}
+
+func NewKeySet(c Config, logger *zap.Logger) (*KeySet, error) {
+	if len(c.Keys) == 0 {
+		logger.Warn("jwt keys are not configured, using ephemeral key: tokens will not survive restart")
+
+		return newEphemeralKeySet()
+	}
+
+	set := &KeySet{keys: make(map[string]*key, len(c.Keys))}
+
+	for _, kc := range c.Keys {
+		k, err := loadKey(kc)
+		if err != nil {
+			return nil, errors.Wrapf(err, "load key %q", kc.ID)
+		}
+
+		if _, ok := set.keys[k.id]; ok {
+			return nil, errors.Errorf("duplicate key id %q", k.id)
+		}
+
+		set.keys[k.id] = k
+
+		if !kc.Active {
+			continue
+		}
+
+		if set.signer != nil {
+			return nil, ErrManyActiveKeys
+		}
+
+		if k.private == nil {
+			return nil, errors.Wrapf(ErrMissingPrivateKey, "key %q", k.id)
+		}
+
+		set.signer = k
+	}
+
+	if set.signer == nil {
+		return nil, ErrNoActiveKey
+	}
+
+	return set, nil
+}
+
+func loadKey(c KeyConfig) (*key, error) {
+	if len(c.ID) == 0 {
+		return nil, errors.New("empty key id")
+	}
+
+	privatePEM, err := pemValue(c.PrivateKey, c.PrivateKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	publicPEM, err := pemValue(c.PublicKey, c.PublicKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(privatePEM) == 0 && len(publicPEM) == 0 {
+		return nil, ErrMissingKeyMaterial
+	}
+
+	k := &key{id: c.ID}
+
+	switch c.Algorithm {
+	case AlgorithmRS256:
+		k.method = jwt.SigningMethodRS256
+
+		if len(privatePEM) != 0 {
+			private, err := jwt.ParseRSAPrivateKeyFromPEM(privatePEM)
+			if err != nil {
+				return nil, err
+			}
+
+			k.private, k.public = private, &private.PublicKey
+		} else {
+			k.public, err = jwt.ParseRSAPublicKeyFromPEM(publicPEM)
+			if err != nil {
+				return nil, err
+			}
+		}
+	case AlgorithmEdDSA:
+		k.method = jwt.SigningMethodEdDSA
+
+		if len(privatePEM) != 0 {
+			private, err := jwt.ParseEdPrivateKeyFromPEM(privatePEM)
+			if err != nil {
+				return nil, err
+			}
+
+			k.private, k.public = private, private.(ed25519.PrivateKey).Public()
+		} else {
+			k.public, err = jwt.ParseEdPublicKeyFromPEM(publicPEM)
+			if err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, errors.Wrap(ErrUnsupportedAlg, c.Algorithm)
+	}
+
+	return k, nil
+}
+
+func pemValue(inline, file string) ([]byte, error) {
+	if len(inline) != 0 {
+		return []byte(inline), nil
+	}
+
+	if len(file) == 0 {
+		return nil, nil
+	}
+
+	return os.ReadFile(file)
+}
+
+func newEphemeralKeySet() (*KeySet, error) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	k := &key{
+		id:      "ephemeral",
+		method:  jwt.SigningMethodEdDSA,
+		private: private,
+		public:  public,
+	}
+
+	return &KeySet{
+		signer: k,
+		keys:   map[string]*key{k.id: k},
+	}, nil
+}
@@ -0,0 +1,89 @@
+package usecases
+
+import (
+	"context"
+	"github.com/labstack/echo/v4"
+	"github.com/samber/lo"
+	"net/http"
+	"twitter-bff/domain/services"
+	"twitter-bff/openapigen/admin"
+	"twitter-bff/usecases/decorators"
+)
+
+// AdminServer реализует admin.yaml. Права на операции проверяет PermissionMiddleware.
+type AdminServer struct {
+	adminSvc         *services.AdminService
+	impersonationSvc *services.ImpersonationService
+}
+
+func (s *AdminServer) ListUsers(echoCtx echo.Context, params admin.ListUsersParams) error {
+	users, err := s.adminSvc.ListUsers(context.Background(), lo.FromPtr(params.Limit))
+	if err != nil {
+		return echoCtx.JSON(ErrorHandler(err))
+	}
+
+	return echoCtx.JSON(http.StatusOK, decorators.AdminUsers(users))
+}
+
+func (s *AdminServer) GetAccount(echoCtx echo.Context, id admin.UserID) error {
+	account, err := s.adminSvc.Account(context.Background(), id)
+	if err != nil {
+		return echoCtx.JSON(ErrorHandler(err))
+	}
+
+	return echoCtx.JSON(http.StatusOK, decorators.AdminAccount(account))
+}
+
+func (s *AdminServer) ForceLogout(echoCtx echo.Context, id admin.UserID) error {
+	err := s.adminSvc.ForceLogout(context.Background(), id)
+	if err != nil {
+		return echoCtx.JSON(ErrorHandler(err))
+	}
+
+	return echoCtx.NoContent(http.StatusNoContent)
+}
+
+func (s *AdminServer) SetPosting(echoCtx echo.Context, id admin.UserID) error {
+	req := &admin.PostingRequest{}
+
+	err := echoCtx.Bind(req)
+	if err != nil {
+		return echoCtx.JSON(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	err = s.adminSvc.SetPostingDisabled(context.Background(), id, req.Disabled)
+	if err != nil {
+		return echoCtx.JSON(ErrorHandler(err))
+	}
+
+	return echoCtx.NoContent(http.StatusNoContent)
+}
+
+func (s *AdminServer) Impersonate(echoCtx echo.Context, id admin.UserID) error {
+	req := &admin.ImpersonateRequest{}
+
+	err := echoCtx.Bind(req)
+	if err != nil {
+		return echoCtx.JSON(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	token, err := s.impersonationSvc.Impersonate(
+		context.Background(),
+		currentUser(echoCtx),
+		id,
+		req.Reason,
+		clientInfo(echoCtx),
+	)
+	if err != nil {
+		return echoCtx.JSON(ErrorHandler(err))
+	}
+
+	return echoCtx.JSON(http.StatusCreated, decorators.AdminImpersonationToken(token))
+}
+
+func NewAdminServer(adminSvc *services.AdminService, impersonationSvc *services.ImpersonationService) *AdminServer {
+	return &AdminServer{
+		adminSvc:         adminSvc,
+		impersonationSvc: impersonationSvc,
+	}
+}
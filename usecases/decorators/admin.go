@@ -0,0 +1,52 @@
+package decorators
+
+import (
+	"github.com/samber/lo"
+	"twitter-bff/domain/models"
+	"twitter-bff/openapigen/admin"
+)
+
+func AdminUser(user models.User) admin.User {
+	return admin.User{
+		Id:       user.ID,
+		Name:     user.Name,
+		Username: user.Username,
+		Email:    user.Email,
+	}
+}
+
+func AdminUsers(users []models.User) admin.UserList {
+	return admin.UserList{
+		Users: lo.Map(users, func(user models.User, _ int) admin.User {
+			return AdminUser(user)
+		}),
+	}
+}
+
+func AdminAccount(account models.AdminAccount) admin.Account {
+	return admin.Account{
+		User:            AdminUser(account.User),
+		Roles:           lo.Ternary(account.Roles != nil, account.Roles, []string{}),
+		EmailVerified:   account.EmailVerified,
+		MfaEnabled:      account.MFAEnabled,
+		PostingDisabled: account.PostingDisabled,
+		Sessions: lo.Map(account.Sessions, func(session models.Session, _ int) admin.Session {
+			return admin.Session{
+				Id:         session.ID,
+				UserAgent:  session.UserAgent,
+				Ip:         session.IP,
+				CreatedAt:  session.CreatedAt,
+				LastSeenAt: session.LastSeenAt,
+				ExpiresAt:  session.ExpiredAt,
+			}
+		}),
+	}
+}
+
+func AdminImpersonationToken(token models.JWTToken) admin.ImpersonationToken {
+	return admin.ImpersonationToken{
+		AccessToken:          token.AccessToken,
+		AccessTokenExpiresAt: token.AccessExpiredAt,
+		UserId:               token.UserID,
+	}
+}
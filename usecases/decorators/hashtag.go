@@ -0,0 +1,16 @@
+package decorators
+
+import (
+	"github.com/samber/lo"
+	"twitter-bff/domain/models"
+	"twitter-bff/openapigen"
+)
+
+func EchoHashtags(hashtags []models.Hashtag) []openapigen.Hashtag {
+	return lo.Map(hashtags, func(hashtag models.Hashtag, _ int) openapigen.Hashtag {
+		return openapigen.Hashtag{
+			Tag:       hashtag.Tag,
+			PostCount: hashtag.PostCount,
+		}
+	})
+}
@@ -0,0 +1,28 @@
+package decorators
+
+import (
+	"twitter-bff/domain/models"
+	"twitter-bff/openapigen"
+)
+
+func EchoJWT(token models.JWTToken) openapigen.JWTResponse {
+	return openapigen.JWTResponse{
+		AccessToken:           token.AccessToken,
+		AccessTokenExpiresAt:  token.AccessExpiredAt,
+		RefreshToken:          token.RefreshToken,
+		RefreshTokenExpiresAt: token.RefreshExpiredAt,
+		CsrfToken:             token.CSRFToken,
+	}
+}
+
+func EchoLoginSession(token models.JWTToken, user models.User) openapigen.LoginSession {
+	return openapigen.LoginSession{
+		SessionId:             token.SessionID,
+		AccessToken:           token.AccessToken,
+		AccessTokenExpiresAt:  token.AccessExpiredAt,
+		RefreshToken:          token.RefreshToken,
+		RefreshTokenExpiresAt: token.RefreshExpiredAt,
+		CsrfToken:             token.CSRFToken,
+		User:                  *EchoUser(user),
+	}
+}
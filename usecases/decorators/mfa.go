@@ -0,0 +1,22 @@
+package decorators
+
+import (
+	"twitter-bff/domain/models"
+	"twitter-bff/openapigen"
+)
+
+func EchoMFAChallenge(result models.LoginResult) openapigen.MFAChallenge {
+	return openapigen.MFAChallenge{
+		MfaToken:          result.MFAToken,
+		MfaTokenExpiresAt: result.MFAExpiredAt,
+		Methods:           []string{models.MFAMethodTOTP, models.MFAMethodRecoveryCode},
+	}
+}
+
+func EchoTOTPEnrollment(enrollment models.TOTPEnrollment) openapigen.TOTPEnrollment {
+	return openapigen.TOTPEnrollment{
+		Secret:     enrollment.Secret,
+		OtpauthUri: enrollment.URI,
+		QrCode:     enrollment.QRCode,
+	}
+}
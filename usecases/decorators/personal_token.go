@@ -0,0 +1,37 @@
+package decorators
+
+import (
+	"github.com/samber/lo"
+	"twitter-bff/domain/models"
+	"twitter-bff/openapigen"
+)
+
+func EchoPersonalToken(token models.PersonalToken) openapigen.PersonalToken {
+	return openapigen.PersonalToken{
+		Id:         token.ID,
+		Name:       token.Name,
+		Scopes:     token.Scopes,
+		CreatedAt:  token.CreatedAt,
+		ExpiresAt:  lo.EmptyableToPtr(token.ExpiredAt),
+		LastUsedAt: lo.EmptyableToPtr(token.LastUsedAt),
+	}
+}
+
+func EchoPersonalTokenCreated(token models.PersonalToken, raw string) openapigen.PersonalTokenCreated {
+	return openapigen.PersonalTokenCreated{
+		Id:        token.ID,
+		Name:      token.Name,
+		Scopes:    token.Scopes,
+		CreatedAt: token.CreatedAt,
+		ExpiresAt: lo.EmptyableToPtr(token.ExpiredAt),
+		Token:     raw,
+	}
+}
+
+func EchoPersonalTokens(tokens []models.PersonalToken) openapigen.PersonalTokenList {
+	return openapigen.PersonalTokenList{
+		Tokens: lo.Map(tokens, func(token models.PersonalToken, _ int) openapigen.PersonalToken {
+			return EchoPersonalToken(token)
+		}),
+	}
+}
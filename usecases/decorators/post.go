@@ -14,16 +14,85 @@ func EchoPosts(posts []models.Post) []openapigen.Post {
 	})
 }
 
+const (
+	feedItemKindPost   = "post"
+	feedItemKindRepost = "repost"
+)
+
+// EchoPostsPage страница постов отдается в том же виде, что и лента, но без репостов
+func EchoPostsPage(page models.PostsPage) openapigen.FeedPage {
+	return openapigen.FeedPage{
+		Items: lo.Map(page.Posts, func(post models.Post, _ int) openapigen.FeedItem {
+			return openapigen.FeedItem{Kind: feedItemKindPost, Post: EchoPost(post)}
+		}),
+		NextCursor: lo.EmptyableToPtr(page.NextCursor),
+		Truncated:  lo.EmptyableToPtr(page.Truncated),
+	}
+}
+
+func EchoFeedPage(page models.FeedPage) openapigen.FeedPage {
+	return openapigen.FeedPage{
+		Items:      lo.Map(page.Items, func(item models.FeedItem, _ int) openapigen.FeedItem { return EchoFeedItem(item) }),
+		NextCursor: lo.EmptyableToPtr(page.NextCursor),
+		Truncated:  lo.EmptyableToPtr(page.Truncated),
+	}
+}
+
+func EchoFeedItem(item models.FeedItem) openapigen.FeedItem {
+	if item.Repost == nil {
+		return openapigen.FeedItem{Kind: feedItemKindPost, Post: EchoPost(item.Post)}
+	}
+
+	return openapigen.FeedItem{
+		Kind:       feedItemKindRepost,
+		Post:       EchoPost(item.Post),
+		RepostedAt: lo.ToPtr(item.Repost.CreatedAt),
+		RepostedBy: EchoUser(item.RepostedBy),
+	}
+}
+
 func EchoPost(post models.Post) openapigen.Post {
 	return openapigen.Post{
-		Body:              post.Body,
-		Comments:          EchoComments(post.Comments),
-		CreatedAt:         openapi_types.Date{Time: post.CreatedAt},
-		Id:                post.ID,
-		LikeCount:         post.LikeCount,
-		IsCurrentUserLike: lo.ToPtr(post.IsCurrentUserLike),
-		UpdatedAt:         openapi_types.Date{Time: post.UpdatedAt},
-		UserId:            fmt.Sprint(post.UserID),
-		User:              EchoUser(post.User),
+		Body:                  post.Body,
+		Comments:              EchoComments(post.Comments),
+		CreatedAt:             openapi_types.Date{Time: post.CreatedAt},
+		Id:                    post.ID,
+		LikeCount:             post.LikeCount,
+		IsCurrentUserLike:     lo.ToPtr(post.IsCurrentUserLike),
+		UpdatedAt:             openapi_types.Date{Time: post.UpdatedAt},
+		ReplyCount:            post.ReplyCount,
+		InReplyToPostId:       lo.EmptyableToPtr(post.InReplyToPostID),
+		RepostCount:           post.RepostCount,
+		IsCurrentUserReposted: lo.ToPtr(post.IsCurrentUserReposted),
+		QuotedPostId:          lo.EmptyableToPtr(post.QuotedPostID),
+		QuotedPost:            echoQuotedPost(post.QuotedPost),
+		UserId:                fmt.Sprint(post.UserID),
+		User:                  EchoUser(post.User),
+	}
+}
+
+func echoQuotedPost(post *models.Post) *openapigen.Post {
+	if post == nil {
+		return nil
 	}
+
+	return lo.ToPtr(EchoPost(*post))
+}
+
+func EchoThread(thread models.Thread) openapigen.Thread {
+	return openapigen.Thread{
+		Ancestors:  EchoPosts(thread.Ancestors),
+		Post:       EchoPost(thread.Post),
+		Replies:    EchoThreadReplies(thread.Replies),
+		NextCursor: lo.EmptyableToPtr(thread.NextCursor),
+	}
+}
+
+func EchoThreadReplies(replies []models.ThreadReply) []openapigen.ThreadReply {
+	return lo.Map(replies, func(reply models.ThreadReply, _ int) openapigen.ThreadReply {
+		return openapigen.ThreadReply{
+			Post:    EchoPost(reply.Post),
+			Replies: EchoThreadReplies(reply.Replies),
+		}
+	})
 }
@@ -0,0 +1,23 @@
+package decorators
+
+import (
+	"github.com/samber/lo"
+	"twitter-bff/domain/models"
+	"twitter-bff/openapigen"
+)
+
+func EchoSessions(sessions []models.Session, currentID string) openapigen.SessionList {
+	return openapigen.SessionList{
+		Sessions: lo.Map(sessions, func(session models.Session, _ int) openapigen.Session {
+			return openapigen.Session{
+				Id:         session.ID,
+				UserAgent:  session.UserAgent,
+				Ip:         session.IP,
+				CreatedAt:  session.CreatedAt,
+				LastSeenAt: session.LastSeenAt,
+				ExpiresAt:  session.ExpiredAt,
+				Current:    session.ID == currentID,
+			}
+		}),
+	}
+}
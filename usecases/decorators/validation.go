@@ -0,0 +1,20 @@
+package decorators
+
+import (
+	"github.com/samber/lo"
+	"twitter-bff/domain/models"
+	"twitter-bff/openapigen"
+)
+
+func EchoValidationError(err models.ValidationError) openapigen.ValidationError {
+	return openapigen.ValidationError{
+		Message: models.ErrInvalidArgument.Error(),
+		Fields: lo.Map(err.Fields, func(field models.FieldError, _ int) openapigen.FieldError {
+			return openapigen.FieldError{
+				Field:   field.Field,
+				Code:    field.Code,
+				Message: field.Message,
+			}
+		}),
+	}
+}
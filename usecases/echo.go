@@ -2,7 +2,6 @@ package usecases
 
 import (
 	"context"
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
 	"github.com/pkg/errors"
 	"github.com/samber/lo"
@@ -13,44 +12,102 @@ import (
 	"twitter-bff/domain/models"
 	"twitter-bff/domain/services"
 	"twitter-bff/openapigen"
+	pkghttp "twitter-bff/pkg/http"
+	"twitter-bff/pkg/keys"
 	"twitter-bff/usecases/decorators"
 )
 
+const (
+	accessTokenCookiePath  = "/"
+	refreshTokenCookiePath = "/api/v1/token"
+	oidcStateCookiePath    = "/api/v1/oauth"
+)
+
+// Коды ошибок /v2/login
+const (
+	loginErrorInvalidRequest     = "invalid_request"
+	loginErrorInvalidCredentials = "invalid_credentials"
+	loginErrorAccountLocked      = "account_locked"
+	loginErrorMFARequired        = "mfa_required"
+)
+
 type EchoServer struct {
-	createSvc         *services.CreateUserService
-	loginSvc          *services.LoginService
-	userByIDService   *services.UserByIDService
-	updateByIDService *services.UpdateUserByIDService
-	postSvc           *services.PostsService
-	followSvc         *services.FollowService
-	likeSvc           *services.LikeService
+	createSvc            *services.CreateUserService
+	loginSvc             *services.LoginService
+	logoutSvc            *services.LogoutService
+	emailVerificationSvc *services.EmailVerificationService
+	mfaSvc               *services.MFAService
+	oidcSvc              *services.OIDCService
+	sessionsSvc          *services.SessionsService
+	personalTokensSvc    *services.PersonalTokensService
+	userByIDService      *services.UserByIDService
+	updateByIDService    *services.UpdateUserByIDService
+	postSvc              *services.PostsService
+	followSvc            *services.FollowService
+	likeSvc              *services.LikeService
+	keySet               *keys.KeySet
+	cookies              pkghttp.CookieConfig
 }
 
-func (s *EchoServer) LoginV2(ctx echo.Context) error {
-	return ctx.JSON(http.StatusOK, "test login v2")
+// JWKS отдает публичные ключи, которыми проверяется подпись access токенов
+func (s *EchoServer) JWKS(echoCtx echo.Context) error {
+	echoCtx.Response().Header().Set(echo.HeaderCacheControl, "public, max-age=300")
+
+	return echoCtx.JSON(http.StatusOK, s.keySet.JWKS())
 }
 
-func (s *EchoServer) Dislike(echoCtx echo.Context, postID int32) error {
-	jUser, err := checkAuth(echoCtx)
+// LoginV2 в отличие от Login сразу отдает текущего пользователя, а ошибки - с кодом из loginError*
+func (s *EchoServer) LoginV2(echoCtx echo.Context) error {
+	req := &openapigen.LoginRequest{}
+
+	err := echoCtx.Bind(req)
+	if err != nil || len(req.Email) == 0 || len(req.Password) == 0 {
+		return echoCtx.JSON(http.StatusUnprocessableEntity, openapigen.LoginError{
+			Code:    loginErrorInvalidRequest,
+			Message: "email and password are required",
+		})
+	}
+
+	ctx := context.Background()
+
+	result, err := s.loginSvc.Login(ctx, string(req.Email), req.Password, clientInfo(echoCtx))
 	if err != nil {
-		return echoCtx.JSON(http.StatusUnauthorized, err.Error())
+		return loginV2ErrorResponse(echoCtx, err)
 	}
 
-	_, err = s.likeSvc.Like(context.Background(), jUser.UserID, postID, models.Dislike)
+	if result.MFARequired {
+		return echoCtx.JSON(http.StatusUnauthorized, openapigen.LoginError{
+			Code:    loginErrorMFARequired,
+			Message: "Second factor required, exchange mfa.mfaToken at /v1/login/mfa",
+			Mfa:     lo.ToPtr(decorators.EchoMFAChallenge(result)),
+		})
+	}
+
+	user, err := s.userByIDService.UserByID(ctx, result.Token.UserID)
 	if err != nil {
 		return echoCtx.JSON(ErrorHandler(err))
 	}
 
-	return echoCtx.JSON(http.StatusNoContent, nil)
+	s.setTokenCookies(echoCtx, result.Token)
+
+	return echoCtx.JSON(http.StatusOK, decorators.EchoLoginSession(result.Token, user))
 }
 
-func (s *EchoServer) Like(echoCtx echo.Context, postID int32) error {
-	jUser, err := checkAuth(echoCtx)
+func (s *EchoServer) Dislike(echoCtx echo.Context, postID int32) error {
+	jUser := currentUser(echoCtx)
+
+	_, err := s.likeSvc.Like(context.Background(), jUser.UserID, postID, models.Dislike)
 	if err != nil {
-		return echoCtx.JSON(http.StatusUnauthorized, err.Error())
+		return echoCtx.JSON(ErrorHandler(err))
 	}
 
-	_, err = s.likeSvc.Like(context.Background(), jUser.UserID, postID, models.Like)
+	return echoCtx.JSON(http.StatusNoContent, nil)
+}
+
+func (s *EchoServer) Like(echoCtx echo.Context, postID int32) error {
+	jUser := currentUser(echoCtx)
+
+	_, err := s.likeSvc.Like(context.Background(), jUser.UserID, postID, models.Like)
 	if err != nil {
 		return echoCtx.JSON(ErrorHandler(err))
 	}
@@ -59,14 +116,11 @@ func (s *EchoServer) Like(echoCtx echo.Context, postID int32) error {
 }
 
 func (s *EchoServer) Unfollow(echoCtx echo.Context) error {
-	jUser, err := checkAuth(echoCtx)
-	if err != nil {
-		return echoCtx.JSON(http.StatusUnauthorized, err.Error())
-	}
+	jUser := currentUser(echoCtx)
 
 	var req openapigen.UnfollowJSONBody
 
-	err = echoCtx.Bind(&req)
+	err := echoCtx.Bind(&req)
 	if err != nil {
 		return echoCtx.JSON(http.StatusUnprocessableEntity, err.Error())
 	}
@@ -90,14 +144,11 @@ func (s *EchoServer) Unfollow(echoCtx echo.Context) error {
 }
 
 func (s *EchoServer) Follow(echoCtx echo.Context) error {
-	jUser, err := checkAuth(echoCtx)
-	if err != nil {
-		return echoCtx.JSON(http.StatusUnauthorized, err.Error())
-	}
+	jUser := currentUser(echoCtx)
 
 	var req openapigen.FollowJSONBody
 
-	err = echoCtx.Bind(&req)
+	err := echoCtx.Bind(&req)
 	if err != nil {
 		return echoCtx.JSON(http.StatusUnprocessableEntity, err.Error())
 	}
@@ -130,7 +181,7 @@ func (s *EchoServer) Comments(echoCtx echo.Context, params openapigen.CommentsPa
 }
 
 func (s *EchoServer) PostById(echoCtx echo.Context, id int32) error {
-	jUser, _ := checkAuth(echoCtx)
+	jUser := currentUser(echoCtx)
 	post, err := s.postSvc.PostByID(context.Background(), id, jUser.UserID)
 	if err != nil {
 		return echoCtx.JSON(ErrorHandler(err))
@@ -142,42 +193,89 @@ func (s *EchoServer) PostById(echoCtx echo.Context, id int32) error {
 func (s *EchoServer) Posts(echoCtx echo.Context, queryParams openapigen.PostsParams) error {
 	ctx := context.Background()
 
-	var (
-		posts []models.Post
-		err   error
-	)
+	page := models.PageRequest{
+		Cursor: lo.FromPtr(queryParams.Cursor),
+		Limit:  lo.FromPtr(queryParams.Limit),
+	}
 
 	if queryParams.UserId != nil {
-		posts, err = s.postSvc.PostsByUserID(ctx, *queryParams.UserId)
+		result, err := s.postSvc.PostsByUserID(ctx, *queryParams.UserId, currentUser(echoCtx).UserID, page)
 		if err != nil {
-			return echoCtx.JSON(ErrorHandler(err))
+			return postsErrorResponse(echoCtx, err)
 		}
 
-		return echoCtx.JSON(http.StatusOK, decorators.EchoPosts(posts))
+		return echoCtx.JSON(http.StatusOK, decorators.EchoPostsPage(result))
 	}
 
-	jUser, err := checkAuth(echoCtx)
+	feed, err := s.postSvc.FeedPosts(ctx, currentUser(echoCtx).UserID, page)
 	if err != nil {
-		return echoCtx.JSON(http.StatusOK, decorators.EchoPosts(posts))
+		return postsErrorResponse(echoCtx, err)
 	}
 
-	posts, err = s.postSvc.FeedPosts(ctx, jUser.UserID)
+	return echoCtx.JSON(http.StatusOK, decorators.EchoFeedPage(feed))
+}
+
+func (s *EchoServer) HashtagPosts(echoCtx echo.Context, tag string, params openapigen.HashtagPostsParams) error {
+	page := models.PageRequest{
+		Cursor: lo.FromPtr(params.Cursor),
+		Limit:  lo.FromPtr(params.Limit),
+	}
+
+	result, err := s.postSvc.HashtagPosts(context.Background(), tag, currentUser(echoCtx).UserID, page)
+	if err != nil {
+		return postsErrorResponse(echoCtx, err)
+	}
+
+	return echoCtx.JSON(http.StatusOK, decorators.EchoPostsPage(result))
+}
+
+func (s *EchoServer) SearchHashtags(echoCtx echo.Context, params openapigen.SearchHashtagsParams) error {
+	hashtags, err := s.postSvc.SearchHashtags(context.Background(), params.Prefix, lo.FromPtr(params.Limit))
+	if err != nil {
+		return postsErrorResponse(echoCtx, err)
+	}
+
+	return echoCtx.JSON(http.StatusOK, decorators.EchoHashtags(hashtags))
+}
+
+func (s *EchoServer) Repost(echoCtx echo.Context, postID openapigen.PostID) error {
+	post, err := s.postSvc.Repost(context.Background(), currentUser(echoCtx).UserID, postID)
 	if err != nil {
 		return echoCtx.JSON(ErrorHandler(err))
 	}
 
-	return echoCtx.JSON(http.StatusOK, decorators.EchoPosts(posts))
+	return echoCtx.JSON(http.StatusCreated, decorators.EchoPost(post))
 }
 
-func (s *EchoServer) CreatePost(echoCtx echo.Context) error {
-	jUser, err := checkAuth(echoCtx)
+func (s *EchoServer) Unrepost(echoCtx echo.Context, postID openapigen.PostID) error {
+	post, err := s.postSvc.Unrepost(context.Background(), currentUser(echoCtx).UserID, postID)
 	if err != nil {
-		return echoCtx.JSON(http.StatusUnauthorized, err.Error())
+		return echoCtx.JSON(ErrorHandler(err))
+	}
+
+	return echoCtx.JSON(http.StatusOK, decorators.EchoPost(post))
+}
+
+func (s *EchoServer) PostThread(echoCtx echo.Context, postID openapigen.PostID, params openapigen.PostThreadParams) error {
+	page := models.PageRequest{
+		Cursor: lo.FromPtr(params.Cursor),
+		Limit:  lo.FromPtr(params.Limit),
+	}
+
+	thread, err := s.postSvc.Thread(context.Background(), postID, currentUser(echoCtx).UserID, page)
+	if err != nil {
+		return postsErrorResponse(echoCtx, err)
 	}
 
+	return echoCtx.JSON(http.StatusOK, decorators.EchoThread(thread))
+}
+
+func (s *EchoServer) CreatePost(echoCtx echo.Context) error {
+	jUser := currentUser(echoCtx)
+
 	var req openapigen.CreatePostJSONBody
 
-	err = echoCtx.Bind(&req)
+	err := echoCtx.Bind(&req)
 	if err != nil {
 		return echoCtx.JSON(http.StatusUnprocessableEntity, err.Error())
 	}
@@ -189,26 +287,20 @@ func (s *EchoServer) CreatePost(echoCtx echo.Context) error {
 
 	ctx := context.Background()
 
-	post, err := s.postSvc.Create(ctx, jUser.UserID, req.Body)
+	post, err := s.postSvc.Create(ctx, jUser.UserID, req.Body, lo.FromPtr(req.InReplyToPostId), lo.FromPtr(req.QuotedPostId))
 	if err != nil {
-		if errors.Is(err, models.ErrInvalidArgument) {
-			return echoCtx.JSON(http.StatusUnprocessableEntity, err.Error())
-		}
-		return echoCtx.JSON(http.StatusInternalServerError, err.Error())
+		return postsErrorResponse(echoCtx, err)
 	}
 
 	return echoCtx.JSON(http.StatusCreated, decorators.EchoPost(post))
 }
 
 func (s *EchoServer) UpdateUser(echoCtx echo.Context) error {
-	jUser, err := checkAuth(echoCtx)
-	if err != nil {
-		return echoCtx.JSON(http.StatusUnauthorized, err.Error())
-	}
+	jUser := currentUser(echoCtx)
 
 	req := &openapigen.UserUpdateRequest{}
 
-	err = echoCtx.Bind(req)
+	err := echoCtx.Bind(req)
 	if err != nil {
 		return echoCtx.JSON(http.StatusUnprocessableEntity, err.Error())
 	}
@@ -235,6 +327,35 @@ func (s *EchoServer) UpdateUser(echoCtx echo.Context) error {
 	return echoCtx.JSON(http.StatusOK, decorators.EchoUser(user))
 }
 
+func (s *EchoServer) VerifyEmail(echoCtx echo.Context) error {
+	req := &openapigen.VerifyEmailRequest{}
+
+	err := echoCtx.Bind(req)
+	if err != nil {
+		return echoCtx.JSON(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	err = s.emailVerificationSvc.Verify(context.Background(), req.Token)
+	if err != nil {
+		return credentialsErrorResponse(echoCtx, err)
+	}
+
+	return echoCtx.NoContent(http.StatusNoContent)
+}
+
+func (s *EchoServer) ResendVerificationEmail(echoCtx echo.Context) error {
+	jUser := currentUser(echoCtx)
+
+	err := s.emailVerificationSvc.Resend(context.Background(), jUser.UserID)
+	if err != nil {
+		return credentialsErrorResponse(echoCtx, err)
+	}
+
+	return echoCtx.JSON(http.StatusAccepted, openapigen.Error{
+		Message: "Verification email has been sent",
+	})
+}
+
 func (s *EchoServer) ListUsers(echoCtx echo.Context) error {
 	users, err := s.userByIDService.NewUsers(context.Background())
 	if err != nil {
@@ -245,28 +366,39 @@ func (s *EchoServer) ListUsers(echoCtx echo.Context) error {
 }
 
 func (s *EchoServer) Logout(echoCtx echo.Context) error {
-	// Создаём cookie с пустым значением и временем истечения в прошлом
-	cookie := &http.Cookie{
-		Name:     models.JWTCookieName, // Имя куки, где хранится JWT токен
-		Value:    "",                   // Очищаем значение
-		Expires:  time.Unix(0, 0),      // Устанавливаем время истечения в прошлом
-		HttpOnly: true,                 // Сохраняем HttpOnly, чтобы обезопасить куки
-		Secure:   false,                // Убедитесь, что Secure соответствует вашим настройкам (true для HTTPS)
+	jUser := currentUser(echoCtx)
+	if jUser.UserID != 0 {
+		err := s.logoutSvc.Logout(context.Background(), jUser)
+		if err != nil {
+			return echoCtx.JSON(ErrorHandler(err))
+		}
 	}
 
-	echoCtx.SetCookie(cookie)
+	s.clearTokenCookies(echoCtx)
 
 	return echoCtx.JSON(http.StatusOK, map[string]string{
 		"message": "Successfully logged out",
 	})
 }
 
-func (s *EchoServer) GetCurrentUser(echoCtx echo.Context) error {
-	jUser, err := checkAuth(echoCtx)
+func (s *EchoServer) LogoutAll(echoCtx echo.Context) error {
+	jUser := currentUser(echoCtx)
+
+	err := s.logoutSvc.LogoutEverywhere(context.Background(), jUser.UserID)
 	if err != nil {
-		return echoCtx.JSON(http.StatusUnauthorized, err.Error())
+		return echoCtx.JSON(ErrorHandler(err))
 	}
 
+	s.clearTokenCookies(echoCtx)
+
+	return echoCtx.JSON(http.StatusOK, map[string]string{
+		"message": "Successfully logged out on all devices",
+	})
+}
+
+func (s *EchoServer) GetCurrentUser(echoCtx echo.Context) error {
+	jUser := currentUser(echoCtx)
+
 	user, err := s.userByIDService.UserByID(context.Background(), jUser.UserID)
 	if errors.Is(err, models.ErrNotFound) {
 		return echoCtx.JSON(http.StatusNotFound, err.Error())
@@ -301,21 +433,255 @@ func (s *EchoServer) Login(echoCtx echo.Context) error {
 		return echoCtx.JSON(http.StatusUnprocessableEntity, "email or password is empty")
 	}
 
-	token, err := s.loginSvc.Login(context.Background(), string(lo.FromPtr(req.Email)), lo.FromPtr(req.Password))
+	result, err := s.loginSvc.Login(
+		context.Background(),
+		string(lo.FromPtr(req.Email)),
+		lo.FromPtr(req.Password),
+		clientInfo(echoCtx),
+	)
 	if err != nil {
-		return echoCtx.JSON(http.StatusUnprocessableEntity, errors.Wrap(err, "cant create token").Error())
+		return credentialsErrorResponse(echoCtx, err)
 	}
 
-	cookie := new(http.Cookie)
-	cookie.HttpOnly = true
-	cookie.Name = models.JWTCookieName
-	cookie.Value = token.Token
+	// cookie выставляются только после второго фактора
+	if result.MFARequired {
+		return echoCtx.JSON(http.StatusAccepted, decorators.EchoMFAChallenge(result))
+	}
 
-	echoCtx.SetCookie(cookie)
+	s.setTokenCookies(echoCtx, result.Token)
 
-	return echoCtx.JSON(http.StatusOK, openapigen.JWTResponse{
-		AccessToken: token.Token,
-	})
+	return echoCtx.JSON(http.StatusOK, decorators.EchoJWT(result.Token))
+}
+
+func (s *EchoServer) LoginMfa(echoCtx echo.Context) error {
+	req := &openapigen.MFALoginRequest{}
+
+	err := echoCtx.Bind(req)
+	if err != nil {
+		return echoCtx.JSON(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	token, err := s.mfaSvc.Verify(context.Background(), req.MfaToken, req.Code, clientInfo(echoCtx))
+	if err != nil {
+		return credentialsErrorResponse(echoCtx, err)
+	}
+
+	s.setTokenCookies(echoCtx, token)
+
+	return echoCtx.JSON(http.StatusOK, decorators.EchoJWT(token))
+}
+
+func (s *EchoServer) OauthAuthorize(echoCtx echo.Context, provider string) error {
+	authURL, state, err := s.oidcSvc.Authorize(context.Background(), provider)
+	if err != nil {
+		return echoCtx.JSON(ErrorHandler(err))
+	}
+
+	// Lax, чтобы cookie пришла при возврате от провайдера обычным переходом
+	echoCtx.SetCookie(s.cookies.Apply(&http.Cookie{
+		Name:     models.OIDCStateCookieName,
+		Value:    state,
+		Path:     oidcStateCookiePath,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}))
+
+	return echoCtx.Redirect(http.StatusFound, authURL)
+}
+
+func (s *EchoServer) OauthCallback(echoCtx echo.Context, provider string, params openapigen.OauthCallbackParams) error {
+	var browserState string
+
+	cookie, err := echoCtx.Cookie(models.OIDCStateCookieName)
+	if err == nil {
+		browserState = cookie.Value
+	}
+
+	echoCtx.SetCookie(s.cookies.Apply(&http.Cookie{
+		Name:     models.OIDCStateCookieName,
+		Path:     oidcStateCookiePath,
+		MaxAge:   -1,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}))
+
+	if params.Error != nil {
+		return echoCtx.Redirect(http.StatusFound, s.oidcSvc.ErrorURL(*params.Error))
+	}
+
+	result, err := s.oidcSvc.Callback(
+		context.Background(),
+		provider,
+		lo.FromPtr(params.State),
+		browserState,
+		lo.FromPtr(params.Code),
+		clientInfo(echoCtx),
+	)
+	if err == nil && !result.MFARequired {
+		s.setTokenCookies(echoCtx, result.Token)
+	}
+
+	return echoCtx.Redirect(http.StatusFound, s.oidcSvc.ResultURL(result, err))
+}
+
+func (s *EchoServer) ListSessions(echoCtx echo.Context) error {
+	jUser := currentUser(echoCtx)
+
+	sessions, err := s.sessionsSvc.List(context.Background(), jUser.UserID)
+	if err != nil {
+		return echoCtx.JSON(ErrorHandler(err))
+	}
+
+	return echoCtx.JSON(http.StatusOK, decorators.EchoSessions(sessions, jUser.SessionID))
+}
+
+func (s *EchoServer) RevokeSession(echoCtx echo.Context, sessionID string) error {
+	jUser := currentUser(echoCtx)
+
+	err := s.sessionsSvc.Revoke(context.Background(), jUser.UserID, sessionID)
+	if err != nil {
+		return echoCtx.JSON(ErrorHandler(err))
+	}
+
+	if sessionID == jUser.SessionID {
+		s.clearTokenCookies(echoCtx)
+	}
+
+	return echoCtx.NoContent(http.StatusNoContent)
+}
+
+func (s *EchoServer) RevokeOtherSessions(echoCtx echo.Context) error {
+	jUser := currentUser(echoCtx)
+
+	err := s.sessionsSvc.RevokeOthers(context.Background(), jUser)
+	if err != nil {
+		return echoCtx.JSON(ErrorHandler(err))
+	}
+
+	return echoCtx.NoContent(http.StatusNoContent)
+}
+
+func (s *EchoServer) ListPersonalTokens(echoCtx echo.Context) error {
+	jUser := currentUser(echoCtx)
+
+	tokens, err := s.personalTokensSvc.List(context.Background(), jUser.UserID)
+	if err != nil {
+		return echoCtx.JSON(ErrorHandler(err))
+	}
+
+	return echoCtx.JSON(http.StatusOK, decorators.EchoPersonalTokens(tokens))
+}
+
+func (s *EchoServer) CreatePersonalToken(echoCtx echo.Context) error {
+	jUser := currentUser(echoCtx)
+
+	req := &openapigen.PersonalTokenCreateRequest{}
+
+	err := echoCtx.Bind(req)
+	if err != nil {
+		return echoCtx.JSON(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	token, raw, err := s.personalTokensSvc.Create(
+		context.Background(),
+		jUser.UserID,
+		req.Name,
+		req.Scopes,
+		lo.FromPtr(req.ExpiresAt),
+	)
+	if err != nil {
+		return credentialsErrorResponse(echoCtx, err)
+	}
+
+	return echoCtx.JSON(http.StatusCreated, decorators.EchoPersonalTokenCreated(token, raw))
+}
+
+func (s *EchoServer) RevokePersonalToken(echoCtx echo.Context, tokenID string) error {
+	jUser := currentUser(echoCtx)
+
+	err := s.personalTokensSvc.Revoke(context.Background(), jUser.UserID, tokenID)
+	if err != nil {
+		return echoCtx.JSON(ErrorHandler(err))
+	}
+
+	return echoCtx.NoContent(http.StatusNoContent)
+}
+
+func (s *EchoServer) EnrollTotp(echoCtx echo.Context) error {
+	jUser := currentUser(echoCtx)
+
+	enrollment, err := s.mfaSvc.Enroll(context.Background(), jUser.UserID)
+	if err != nil {
+		return credentialsErrorResponse(echoCtx, err)
+	}
+
+	// секрет не должен оседать в кэшах
+	echoCtx.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+
+	return echoCtx.JSON(http.StatusOK, decorators.EchoTOTPEnrollment(enrollment))
+}
+
+func (s *EchoServer) ConfirmTotp(echoCtx echo.Context) error {
+	jUser := currentUser(echoCtx)
+
+	req := &openapigen.MFACodeRequest{}
+
+	err := echoCtx.Bind(req)
+	if err != nil {
+		return echoCtx.JSON(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	codes, err := s.mfaSvc.Confirm(context.Background(), jUser.UserID, req.Code)
+	if err != nil {
+		return credentialsErrorResponse(echoCtx, err)
+	}
+
+	echoCtx.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+
+	return echoCtx.JSON(http.StatusOK, openapigen.RecoveryCodes{RecoveryCodes: codes})
+}
+
+func (s *EchoServer) DisableTotp(echoCtx echo.Context) error {
+	jUser := currentUser(echoCtx)
+
+	req := &openapigen.MFACodeRequest{}
+
+	err := echoCtx.Bind(req)
+	if err != nil {
+		return echoCtx.JSON(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	err = s.mfaSvc.Disable(context.Background(), jUser.UserID, req.Code)
+	if err != nil {
+		return credentialsErrorResponse(echoCtx, err)
+	}
+
+	return echoCtx.NoContent(http.StatusNoContent)
+}
+
+func (s *EchoServer) RefreshToken(echoCtx echo.Context) error {
+	req := &openapigen.RefreshTokenJSONBody{}
+
+	if err := echoCtx.Bind(req); err != nil {
+		return echoCtx.JSON(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	refreshToken := lo.FromPtr(req.RefreshToken)
+	if len(refreshToken) == 0 {
+		cookie, err := echoCtx.Cookie(models.RefreshTokenCookieName)
+		if err == nil {
+			refreshToken = cookie.Value
+		}
+	}
+
+	token, err := s.loginSvc.Refresh(context.Background(), refreshToken, clientInfo(echoCtx))
+	if err != nil {
+		return echoCtx.JSON(ErrorHandler(err))
+	}
+
+	s.setTokenCookies(echoCtx, token)
+
+	return echoCtx.JSON(http.StatusOK, decorators.EchoJWT(token))
 }
 
 func (s *EchoServer) CreateUser(echoCtx echo.Context) error {
@@ -333,66 +699,175 @@ func (s *EchoServer) CreateUser(echoCtx echo.Context) error {
 
 	ctx := context.Background()
 
-	user, err := s.createSvc.Create(ctx, req.Name, req.Password, req.Name, string(req.Email))
+	user, err := s.createSvc.Create(ctx, req.Name, req.Password, req.Username, string(req.Email))
 	if err != nil {
+		var validationErr models.ValidationError
+		if errors.As(err, &validationErr) {
+			return echoCtx.JSON(http.StatusUnprocessableEntity, decorators.EchoValidationError(validationErr))
+		}
+
 		return echoCtx.JSON(http.StatusInternalServerError, err.Error())
 	}
 
 	return echoCtx.JSON(http.StatusCreated, decorators.EchoUser(user))
 }
 
-func jwtUser(echoCtx echo.Context) (models.JWTUser, error) {
-	mapClaims, ok := echoCtx.Get("user").(jwt.MapClaims) // by default token is stored under `user` key
-	if !ok {
-		return models.JWTUser{}, errors.New("failed to cast claims as jwt.MapClaims")
+func (s *EchoServer) setTokenCookies(echoCtx echo.Context, token models.JWTToken) {
+	echoCtx.SetCookie(s.cookies.Apply(&http.Cookie{
+		Name:     models.JWTCookieName,
+		Value:    token.AccessToken,
+		Path:     accessTokenCookiePath,
+		Expires:  token.AccessExpiredAt,
+		HttpOnly: true,
+	}))
+
+	// refresh токен нужен только эндпоинту обновления, поэтому не отправляем его с каждым запросом
+	echoCtx.SetCookie(s.cookies.Apply(&http.Cookie{
+		Name:     models.RefreshTokenCookieName,
+		Value:    token.RefreshToken,
+		Path:     refreshTokenCookiePath,
+		Expires:  token.RefreshExpiredAt,
+		HttpOnly: true,
+	}))
+
+	// без HttpOnly: фронтенд читает значение и передает его в заголовке X-CSRF-Token
+	echoCtx.SetCookie(s.cookies.Apply(&http.Cookie{
+		Name:    models.CSRFCookieName,
+		Value:   token.CSRFToken,
+		Path:    accessTokenCookiePath,
+		Expires: token.AccessExpiredAt,
+	}))
+}
+
+func (s *EchoServer) clearTokenCookies(echoCtx echo.Context) {
+	// Создаём cookie с пустым значением и временем истечения в прошлом
+	echoCtx.SetCookie(s.cookies.Apply(&http.Cookie{
+		Name:     models.JWTCookieName, // Имя куки, где хранится JWT токен
+		Value:    "",                   // Очищаем значение
+		Path:     accessTokenCookiePath,
+		Expires:  time.Unix(0, 0), // Устанавливаем время истечения в прошлом
+		HttpOnly: true,            // Сохраняем HttpOnly, чтобы обезопасить куки
+	}))
+	echoCtx.SetCookie(s.cookies.Apply(&http.Cookie{
+		Name:     models.RefreshTokenCookieName,
+		Value:    "",
+		Path:     refreshTokenCookiePath,
+		Expires:  time.Unix(0, 0),
+		HttpOnly: true,
+	}))
+	echoCtx.SetCookie(s.cookies.Apply(&http.Cookie{
+		Name:    models.CSRFCookieName,
+		Value:   "",
+		Path:    accessTokenCookiePath,
+		Expires: time.Unix(0, 0),
+	}))
+}
+
+func loginV2ErrorResponse(echoCtx echo.Context, err error) error {
+	var lockedErr models.LoginLockedError
+	if errors.As(err, &lockedErr) {
+		retryAfter := int(lockedErr.RetryAfter.Seconds())
+		echoCtx.Response().Header().Set(echo.HeaderRetryAfter, strconv.Itoa(retryAfter))
+
+		return echoCtx.JSON(http.StatusTooManyRequests, openapigen.LoginError{
+			Code:       loginErrorAccountLocked,
+			Message:    "Too many failed login attempts",
+			RetryAfter: lo.ToPtr(retryAfter),
+		})
+	}
+
+	if errors.Is(err, models.ErrInvalidCredentials) {
+		return echoCtx.JSON(http.StatusUnauthorized, openapigen.LoginError{
+			Code:    loginErrorInvalidCredentials,
+			Message: "Invalid email or password",
+		})
+	}
+
+	return echoCtx.JSON(ErrorHandler(err))
+}
+
+// credentialsErrorResponse отвечает на ошибки проверки пароля: ошибки полей отдаются как 422,
+// при блокировке выставляется Retry-After
+func credentialsErrorResponse(echoCtx echo.Context, err error) error {
+	var validationErr models.ValidationError
+	if errors.As(err, &validationErr) {
+		return echoCtx.JSON(http.StatusUnprocessableEntity, decorators.EchoValidationError(validationErr))
 	}
 
-	expiredAt, err := mapClaims.GetExpirationTime()
-	if err != nil {
-		return models.JWTUser{}, errors.Wrap(err, "cant get token expiration time")
+	var lockedErr models.LoginLockedError
+	if errors.As(err, &lockedErr) {
+		echoCtx.Response().Header().Set(echo.HeaderRetryAfter, strconv.Itoa(int(lockedErr.RetryAfter.Seconds())))
 	}
 
-	sub, err := mapClaims.GetSubject()
-	if err != nil {
-		return models.JWTUser{}, errors.Wrap(err, "cant get token subject")
+	var rateLimitedErr models.RateLimitedError
+	if errors.As(err, &rateLimitedErr) {
+		echoCtx.Response().Header().Set(echo.HeaderRetryAfter, strconv.Itoa(int(rateLimitedErr.RetryAfter.Seconds())))
 	}
 
-	userID, err := strconv.ParseInt(sub, 10, 32)
-	if err != nil {
-		return models.JWTUser{}, errors.Wrap(err, "cant parse user id")
+	status, message := ErrorHandler(err)
+
+	return echoCtx.JSON(status, openapigen.Error{Message: message})
+}
+
+// postsErrorResponse отвечает на ошибки постов: ошибки полей отдаются как 422, остальное - через ErrorHandler
+func postsErrorResponse(echoCtx echo.Context, err error) error {
+	var validationErr models.ValidationError
+	if errors.As(err, &validationErr) {
+		return echoCtx.JSON(http.StatusUnprocessableEntity, decorators.EchoValidationError(validationErr))
 	}
 
-	return models.JWTUser{
-		UserID:    int32(userID),
-		ExpiredAt: expiredAt.Time,
-	}, nil
+	status, message := ErrorHandler(err)
+
+	return echoCtx.JSON(status, openapigen.Error{Message: message})
 }
 
-func checkAuth(echoCtx echo.Context) (models.JWTUser, error) {
-	jUser, err := jwtUser(echoCtx)
-	if err != nil {
-		return models.JWTUser{}, err
+func clientInfo(echoCtx echo.Context) models.ClientInfo {
+	return models.ClientInfo{
+		IP:        echoCtx.RealIP(),
+		UserAgent: echoCtx.Request().UserAgent(),
 	}
+}
+
+// currentUser возвращает пользователя, которого AuthMiddleware положил в контекст.
+// Для анонимного запроса к операции с необязательной аутентификацией UserID равен 0.
+func currentUser(echoCtx echo.Context) models.JWTUser {
+	jUser, _ := echoCtx.Get(models.JWTUserContextKey).(models.JWTUser)
 
-	return jUser, nil
+	return jUser
 }
 
 func NewEchoServer(
 	createSvc *services.CreateUserService,
 	loginSvc *services.LoginService,
+	logoutSvc *services.LogoutService,
+	emailVerificationSvc *services.EmailVerificationService,
+	mfaSvc *services.MFAService,
+	oidcSvc *services.OIDCService,
+	sessionsSvc *services.SessionsService,
+	personalTokensSvc *services.PersonalTokensService,
 	currentUserSvc *services.UserByIDService,
 	updateUserSvc *services.UpdateUserByIDService,
 	postSvc *services.PostsService,
 	followSvc *services.FollowService,
 	likeSvc *services.LikeService,
+	keySet *keys.KeySet,
+	httpConfig pkghttp.Config,
 ) *EchoServer {
 	return &EchoServer{
-		createSvc:         createSvc,
-		loginSvc:          loginSvc,
-		userByIDService:   currentUserSvc,
-		updateByIDService: updateUserSvc,
-		postSvc:           postSvc,
-		followSvc:         followSvc,
-		likeSvc:           likeSvc,
+		createSvc:            createSvc,
+		loginSvc:             loginSvc,
+		logoutSvc:            logoutSvc,
+		emailVerificationSvc: emailVerificationSvc,
+		mfaSvc:               mfaSvc,
+		oidcSvc:              oidcSvc,
+		sessionsSvc:          sessionsSvc,
+		personalTokensSvc:    personalTokensSvc,
+		userByIDService:      currentUserSvc,
+		updateByIDService:    updateUserSvc,
+		postSvc:              postSvc,
+		followSvc:            followSvc,
+		likeSvc:              likeSvc,
+		keySet:               keySet,
+		cookies:              httpConfig.Cookie,
 	}
 }
@@ -11,6 +11,50 @@ func ErrorHandler(err error) (int, string) {
 		return http.StatusUnprocessableEntity, err.Error()
 	}
 
+	if errors.Is(err, models.ErrInvalidRefreshToken) || errors.Is(err, models.ErrRefreshTokenReused) {
+		return http.StatusUnauthorized, err.Error()
+	}
+
+	if errors.Is(err, models.ErrInvalidMFAToken) || errors.Is(err, models.ErrMFAChallengeReused) {
+		return http.StatusUnauthorized, err.Error()
+	}
+
+	if errors.Is(err, models.ErrMFAAlreadyEnabled) || errors.Is(err, models.ErrMFANotEnrolled) {
+		return http.StatusConflict, err.Error()
+	}
+
+	if errors.Is(err, models.ErrInvalidCredentials) {
+		return http.StatusUnauthorized, err.Error()
+	}
+
+	if errors.Is(err, models.ErrLoginLocked) {
+		return http.StatusTooManyRequests, err.Error()
+	}
+
+	if errors.Is(err, models.ErrTooManyRequests) {
+		return http.StatusTooManyRequests, err.Error()
+	}
+
+	if errors.Is(err, models.ErrEmailNotVerified) {
+		return http.StatusForbidden, err.Error()
+	}
+
+	if errors.Is(err, models.ErrImpersonationNotAllowed) {
+		return http.StatusForbidden, err.Error()
+	}
+
+	if errors.Is(err, models.ErrPostingDisabled) {
+		return http.StatusForbidden, err.Error()
+	}
+
+	if errors.Is(err, models.ErrEmailAlreadyVerified) {
+		return http.StatusConflict, err.Error()
+	}
+
+	if errors.Is(err, models.ErrUnknownOIDCProvider) {
+		return http.StatusNotFound, err.Error()
+	}
+
 	if errors.Is(err, models.ErrNotFound) {
 		return http.StatusNotFound, err.Error()
 	}